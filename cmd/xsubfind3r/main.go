@@ -2,20 +2,36 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/hueristiq/hqgolog"
 	"github.com/hueristiq/hqgolog/formatter"
 	"github.com/hueristiq/hqgolog/levels"
 	"github.com/hueristiq/xsubfind3r/internal/configuration"
+	"github.com/hueristiq/xsubfind3r/pkg/notifier"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/checkpoint"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/filter"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/graph"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/normalizer"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sqlite"
 	"github.com/logrusorgru/aurora/v3"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -27,12 +43,36 @@ var (
 	configurationFilePath string
 	domains               []string
 	domainsListFilePath   string
+	resume                bool
+	restart               bool
+	checkpointFilePath    string
 	listSources           bool
 	sourcesToUse          []string
+	sourcesToInclude      []string
 	sourcesToExclude      []string
+	wordlist              string
+	fingerprints          string
+	paths                 []string
+	imports               []string
+	resolvers             []string
+	enumerateRoots        bool
+	asnDatabases          []string
+	resolve               bool
+	excludeASN            []string
+	probing               bool
+	clustering            bool
+	match                 []string
+	filterOut             []string
+	scopeFile             string
+	outOfScopeFile        string
+	stripWWW              bool
 	monochrome            bool
+	jsonLines             bool
 	output                string
 	outputDirectory       string
+	outputSQLite          string
+	outputGraph           []string
+	notify                bool
 	silent                bool
 	verbose               bool
 )
@@ -42,12 +82,36 @@ func init() {
 	pflag.StringVarP(&configurationFilePath, "configuration", "c", configuration.ConfigurationFilePath, "")
 	pflag.StringSliceVarP(&domains, "domain", "d", []string{}, "")
 	pflag.StringVarP(&domainsListFilePath, "list", "l", "", "")
+	pflag.BoolVar(&resume, "resume", false, "")
+	pflag.BoolVar(&restart, "restart", false, "")
+	pflag.StringVar(&checkpointFilePath, "checkpoint", "", "")
 	pflag.BoolVar(&listSources, "sources", false, "")
 	pflag.StringSliceVarP(&sourcesToUse, "use-sources", "u", []string{}, "")
+	pflag.StringSliceVarP(&sourcesToInclude, "include-sources", "i", []string{}, "")
 	pflag.StringSliceVarP(&sourcesToExclude, "exclude-sources", "e", []string{}, "")
+	pflag.StringVarP(&wordlist, "wordlist", "w", "", "")
+	pflag.StringVar(&fingerprints, "fingerprints", "", "")
+	pflag.StringSliceVar(&paths, "path", []string{}, "")
+	pflag.StringSliceVar(&imports, "import", []string{}, "")
+	pflag.StringSliceVarP(&resolvers, "resolvers", "r", []string{}, "")
+	pflag.BoolVar(&enumerateRoots, "enumerate-roots", false, "")
+	pflag.StringSliceVar(&asnDatabases, "asn-database", []string{}, "")
+	pflag.BoolVar(&resolve, "resolve", false, "")
+	pflag.StringSliceVar(&excludeASN, "exclude-asn", []string{}, "")
+	pflag.BoolVar(&probing, "probe", false, "")
+	pflag.BoolVar(&clustering, "cluster", false, "")
+	pflag.StringArrayVarP(&match, "match", "m", []string{}, "")
+	pflag.StringArrayVarP(&filterOut, "filter", "f", []string{}, "")
+	pflag.StringVar(&scopeFile, "scope", "", "")
+	pflag.StringVar(&outOfScopeFile, "out-of-scope", "", "")
+	pflag.BoolVar(&stripWWW, "strip-www", false, "")
 	pflag.BoolVar(&monochrome, "monochrome", false, "")
+	pflag.BoolVar(&jsonLines, "json", false, "")
 	pflag.StringVarP(&output, "output", "o", "", "")
 	pflag.StringVarP(&outputDirectory, "output-directory", "O", "", "")
+	pflag.StringVar(&outputSQLite, "output-sqlite", "", "")
+	pflag.StringSliceVar(&outputGraph, "output-graph", []string{}, "")
+	pflag.BoolVar(&notify, "notify", false, "")
 	pflag.BoolVarP(&silent, "silent", "s", false, "")
 	pflag.BoolVarP(&verbose, "verbose", "v", false, "")
 
@@ -65,6 +129,9 @@ func init() {
 		h += "\nINPUT:\n"
 		h += " -d, --domain string[]                 target domain\n"
 		h += " -l, --list string                     target domains list file path\n"
+		h += "     --resume bool                     resume an interrupted run, skipping finished work\n"
+		h += "     --restart bool                    discard the checkpoint of an interrupted run, starting over\n"
+		h += "     --checkpoint string               checkpoint file path (default: one per set of domains)\n"
 
 		h += "\nTIP: For multiple input domains use comma(,) separated value with `-d`,\n"
 		h += "     specify multiple `-d`, load from file with `-l` or load from stdin.\n"
@@ -72,12 +139,43 @@ func init() {
 		h += "\nSOURCES:\n"
 		h += "     --sources bool                    list supported sources\n"
 		h += " -u, --sources-to-use string[]         comma(,) separated sources to use\n"
+		h += " -i, --include-sources string[]        comma(,) separated opt-in sources to add\n"
 		h += " -e, --sources-to-exclude string[]     comma(,) separated sources to exclude\n"
+		h += " -w, --wordlist string                 bruteforce wordlist file path\n"
+		h += "     --fingerprints string             takeover fingerprints YAML file path\n"
+		h += "     --path string[]                   comma(,) separated local paths to search (localfs)\n"
+		h += "     --import string[]                 comma(,) separated tool output files to import\n"
+		h += " -r, --resolvers string[]              comma(,) separated DNS resolvers to use\n"
+		h += "     --enumerate-roots bool            find subdomains of sibling root domains found (whois)\n"
+
+		h += "\nTIP: Active sources, which query the target's infrastructure, are opt-in:\n"
+		h += "     add them with `-i` (e.g. `-i bruteforce,dnsrecords,zonewalk`).\n"
+
+		h += "\nENRICHMENT:\n"
+		h += "     --asn-database string[]           comma(,) separated ASN database (MaxMind .mmdb, iptoasn .tsv) file paths\n"
+		h += "     --resolve bool                    resolve subdomains to their IP addresses\n"
+		h += "     --exclude-asn string[]            comma(,) separated ASNs (e.g. AS13335) whose subdomains to drop\n"
+		h += "     --probe bool                      probe the web servers of subdomains found over HTTP(S)\n"
+		h += "     --cluster bool                    cluster web servers probed by similarity of their responses\n"
+
+		h += "\nFILTERS:\n"
+		h += " -m, --match string[]                  pattern subdomains must match (repeatable)\n"
+		h += " -f, --filter string[]                 pattern of subdomains to drop (repeatable)\n"
+		h += "     --scope string                    in-scope hosts list file path\n"
+		h += "     --out-of-scope string             out-of-scope hosts list file path\n"
+		h += "     --strip-www bool                  fold www.<subdomain> onto <subdomain>\n"
+
+		h += "\nTIP: Patterns are globs (e.g. `*.api.*`), or regular expressions if\n"
+		h += "     prefixed with `regex:` (e.g. `regex:^dev[0-9]+\\.`).\n"
 
 		h += "\nOUTPUT:\n"
 		h += "     --monochrome bool                 display no color output\n"
+		h += "     --json bool                       output results as JSON lines, IP addresses and related domains included\n"
 		h += " -o, --output string                   output subdomains file path\n"
 		h += " -O, --output-directory string         output subdomains directory path\n"
+		h += "     --output-sqlite string            output results SQLite database file path, appended to\n"
+		h += "     --output-graph string[]           comma(,) separated output graph file paths (.dot, .graphml, .json)\n"
+		h += "     --notify bool                     send findings to configured notification webhooks\n"
 		h += " -s, --silent bool                     display output subdomains only\n"
 		h += " -v, --verbose bool                    display verbose output\n"
 
@@ -152,6 +250,14 @@ func main() {
 			}
 		}
 
+		hqgolog.Print().Msg("")
+		hqgolog.Info().Msgf("opt-in sources, added with %v:", au.Underline("--include-sources").Bold())
+		hqgolog.Print().Msg("")
+
+		for _, source := range sources.OptIn {
+			hqgolog.Print().Msgf("> %s", source)
+		}
+
 		hqgolog.Print().Msg("")
 
 		os.Exit(0)
@@ -198,11 +304,135 @@ func main() {
 		}
 	}
 
+	// record progress, so that an interrupted run can be resumed with `--resume`.
+	// Each set of domains has a checkpoint of its own, so that runs for other
+	// targets, concurrent or not, leave it be.
+	if resume && restart {
+		hqgolog.Fatal().Msgf("%v and %v are mutually exclusive", au.Underline("--resume").Bold(), au.Underline("--restart").Bold())
+	}
+
+	if checkpointFilePath == "" {
+		checkpointFilePath = filepath.Join(configuration.CheckpointsDirectoryPath, checkpointName(domains)+".jsonl")
+	}
+
+	// the checkpoint of an interrupted run is only discarded on request.
+	if _, err = os.Stat(checkpointFilePath); err == nil && !resume && !restart {
+		hqgolog.Fatal().Msgf("%s: an interrupted run of these domains can be continued with %v, or discarded with %v", checkpointFilePath, au.Underline("--resume").Bold(), au.Underline("--restart").Bold())
+	}
+
+	var progress *checkpoint.Checkpoint
+
+	progress, err = checkpoint.Open(checkpointFilePath, resume)
+	if err != nil {
+		hqgolog.Fatal().Msg(err.Error())
+	}
+
+	// build the scope filter.
+	filterCfg := &filter.Configuration{
+		Match:  match,
+		Filter: filterOut,
+	}
+
+	if scopeFile != "" {
+		filterCfg.ScopeFiles = append(filterCfg.ScopeFiles, scopeFile)
+	}
+
+	if outOfScopeFile != "" {
+		filterCfg.OutOfScopeFiles = append(filterCfg.OutOfScopeFiles, outOfScopeFile)
+	}
+
+	var scope *filter.Filter
+
+	scope, err = filter.New(filterCfg)
+	if err != nil {
+		hqgolog.Fatal().Msg(err.Error())
+	}
+
+	if wordlist != "" {
+		config.Bruteforce.Wordlist = wordlist
+	}
+
+	if fingerprints != "" {
+		config.Takeover.Fingerprints = fingerprints
+	}
+
+	if len(paths) > 0 {
+		config.LocalFS.Paths = append(config.LocalFS.Paths, paths...)
+	}
+
+	if len(imports) > 0 {
+		config.Import.Files = append(config.Import.Files, imports...)
+	}
+
+	if len(resolvers) > 0 {
+		config.Resolvers = resolvers
+	}
+
+	if len(asnDatabases) > 0 {
+		config.Enrichment.Databases = asnDatabases
+	}
+
+	if resolve {
+		config.Enrichment.Resolve = true
+	}
+
+	if len(excludeASN) > 0 {
+		config.Enrichment.ExcludeASN = append(config.Enrichment.ExcludeASN, excludeASN...)
+	}
+
+	if probing {
+		config.Probe.Enabled = true
+	}
+
+	// clustering builds on probing, it turns it on.
+	if clustering {
+		config.Probe.Enabled = true
+		config.Probe.Cluster = true
+	}
+
+	var namespace *graph.Graph
+
+	if len(outputGraph) > 0 {
+		for _, path := range outputGraph {
+			if _, err = graph.FormatOf(path); err != nil {
+				hqgolog.Fatal().Msgf("%s: %s", path, err)
+			}
+		}
+
+		namespace = graph.New()
+	}
+
 	// scrape and output subdomains.
 	cfg := &xsubfind3r.Configuration{
 		SourcesToUSe:     sourcesToUse,
+		SourcesToInclude: sourcesToInclude,
 		SourcesToExclude: sourcesToExclude,
 		Keys:             config.Keys,
+		CTLog:            config.CTLog,
+		GitLab:           config.GitLab,
+		LocalFS:          config.LocalFS,
+		Import:           config.Import,
+		PassiveDNS:       config.PassiveDNS,
+		HostSearch:       config.HostSearch,
+		Whois:            config.Whois,
+		Resolvers:        config.Resolvers,
+		Retries:          config.Retries,
+		Bruteforce:       config.Bruteforce,
+		Permutation:      config.Permutation,
+		ZoneWalk:         config.ZoneWalk,
+		DNSRecords:       config.DNSRecords,
+		TLSGrab:          config.TLSGrab,
+		Crawl:            config.Crawl,
+		Takeover:         config.Takeover,
+		Enrichment:       config.Enrichment,
+		Probe:            config.Probe,
+		Attributions:     outputSQLite != "",
+		Graph:            namespace,
+		Checkpoint:       progress,
+		Filter:           scope,
+		Normalizer: normalizer.New(&normalizer.Configuration{
+			StripWWW: stripWWW,
+		}),
 	}
 
 	var finder *xsubfind3r.Finder
@@ -214,6 +444,47 @@ func main() {
 		return
 	}
 
+	var notifications *notifier.Notifier
+
+	if notify {
+		notifications, err = notifier.New(&config.Notifications)
+		if err != nil {
+			hqgolog.Fatal().Msg(err.Error())
+		}
+	}
+
+	var database *sqlite.Store
+
+	if outputSQLite != "" {
+		mkdir(filepath.Dir(outputSQLite))
+
+		database, err = sqlite.Open(outputSQLite, configuration.VERSION, os.Args[1:])
+		if err != nil {
+			hqgolog.Fatal().Msg(err.Error())
+		}
+	}
+
+	// cancel in-flight sources on SIGINT/SIGTERM, then drain their results, flush
+	// and close outputs before exiting. A second signal kills the process at once.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		stop()
+	}()
+
+	p := &processor{
+		notifications: notifications,
+		database:      database,
+		progress:      progress,
+		statistics:    map[string]*statistic{},
+	}
+
+	var consolidatedFile *os.File
+
 	var consolidatedWriter *bufio.Writer
 
 	if output != "" {
@@ -221,15 +492,11 @@ func main() {
 
 		mkdir(directory)
 
-		var consolidatedFile *os.File
-
 		consolidatedFile, err = os.OpenFile(output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 		if err != nil {
 			hqgolog.Fatal().Msg(err.Error())
 		}
 
-		defer consolidatedFile.Close()
-
 		consolidatedWriter = bufio.NewWriter(consolidatedFile)
 	}
 
@@ -237,7 +504,21 @@ func main() {
 		mkdir(outputDirectory)
 	}
 
-	for index := range domains {
+	// sibling root domains found for the input domains are enumerated after
+	// them, those found for sibling root domains are not.
+	inputs := len(domains)
+
+	queued := map[string]struct{}{}
+
+	for _, domain := range domains {
+		queued[domain] = struct{}{}
+	}
+
+	for index := 0; index < len(domains); index++ {
+		if ctx.Err() != nil {
+			break
+		}
+
 		domain := domains[index]
 
 		if !silent {
@@ -246,28 +527,120 @@ func main() {
 			hqgolog.Print().Msg("")
 		}
 
-		subdomains := finder.Find(domain)
+		subdomains := finder.Find(ctx, domain)
 
 		switch {
 		case output != "":
-			processSubdomains(consolidatedWriter, subdomains)
+			p.process(domain, consolidatedWriter, subdomains)
 		case outputDirectory != "":
 			var domainFile *os.File
 
-			domainFile, err = os.OpenFile(filepath.Join(outputDirectory, domain+".txt"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+			extension := ".txt"
+
+			if jsonLines {
+				extension = ".jsonl"
+			}
+
+			domainFile, err = os.OpenFile(filepath.Join(outputDirectory, domain+extension), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 			if err != nil {
 				hqgolog.Fatal().Msg(err.Error())
 			}
 
 			domainWriter := bufio.NewWriter(domainFile)
 
-			processSubdomains(domainWriter, subdomains)
+			p.process(domain, domainWriter, subdomains)
+
+			closeOutput(domainWriter, domainFile)
 		default:
-			processSubdomains(nil, subdomains)
+			p.process(domain, nil, subdomains)
 		}
+
+		// roots are read back from the checkpoint, which also holds those
+		// found before a resume, by sources skipped since.
+		if enumerateRoots && index < inputs {
+			for _, root := range progress.Roots(domain) {
+				if _, ok := queued[root]; ok {
+					continue
+				}
+
+				queued[root] = struct{}{}
+
+				domains = append(domains, root)
+			}
+		}
+
+		if notifications != nil {
+			if err = notifications.Flush(context.Background()); err != nil {
+				hqgolog.Error().Msg(err.Error())
+			}
+		}
+
+		if database != nil {
+			if err = database.Flush(); err != nil {
+				hqgolog.Error().Msg(err.Error())
+			}
+		}
+	}
+
+	// pending events were flushed after each domain, stop delivering.
+	if notifications != nil {
+		notifications.Close()
+	}
+
+	if database != nil {
+		if err = database.Close(ctx.Err() != nil); err != nil {
+			hqgolog.Error().Msg(err.Error())
+		}
+	}
+
+	// the graph is written out once all domains are searched, interrupted runs included.
+	for _, path := range outputGraph {
+		mkdir(filepath.Dir(path))
+
+		if err = namespace.WriteFile(path); err != nil {
+			hqgolog.Error().Msg(err.Error())
+		}
+	}
+
+	if consolidatedFile != nil {
+		closeOutput(consolidatedWriter, consolidatedFile)
+	}
+
+	if ctx.Err() != nil {
+		// keep the checkpoint, the run can be continued with `--resume`.
+		if err = progress.Close(); err != nil {
+			hqgolog.Error().Msg(err.Error())
+		}
+
+		p.summarize()
+
+		os.Exit(exitCodeInterrupted)
+	}
+
+	// the run completed, there is nothing left to resume.
+	if err = progress.Remove(); err != nil {
+		hqgolog.Error().Msg(err.Error())
 	}
 }
 
+// checkpointName returns the name of the checkpoint of a run for domains,
+// the same whatever their order or case.
+func checkpointName(domains []string) string {
+	normalized := make([]string, 0, len(domains))
+
+	for _, domain := range domains {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(domain)))
+	}
+
+	sort.Strings(normalized)
+
+	normalized = slices.Compact(normalized)
+
+	sum := sha256.Sum256([]byte(strings.Join(normalized, "\n")))
+
+	return hex.EncodeToString(sum[:8])
+}
+
 func hasStdin() bool {
 	stat, err := os.Stdin.Stat()
 	if err != nil {
@@ -288,27 +661,447 @@ func mkdir(path string) {
 	}
 }
 
-func processSubdomains(writer *bufio.Writer, subdomains chan sources.Result) {
+// exitCodeInterrupted is the exit code of a run stopped by SIGINT or SIGTERM,
+// following the shell convention of 128 plus the signal number of SIGINT.
+const exitCodeInterrupted = 130
+
+// processor consumes the results of a run, writing subdomains out and
+// keeping per source statistics.
+type processor struct {
+	notifications *notifier.Notifier
+	database      *sqlite.Store
+	progress      *checkpoint.Checkpoint
+	statistics    map[string]*statistic
+}
+
+type statistic struct {
+	subdomains int
+	errors     int
+}
+
+// process writes out and displays the results found for domain, and returns
+// the sibling root domains found, for them to be enumerated.
+func (p *processor) process(domain string, writer *bufio.Writer, subdomains chan sources.Result) {
+	var related, siblings, candidates, servers []sources.Result
+
+	// providers counts the subdomains hosted per autonomous system, if enriched.
+	providers := map[string]int{}
+
 	for subdomain := range subdomains {
+		if _, ok := p.statistics[subdomain.Source]; !ok {
+			p.statistics[subdomain.Source] = &statistic{}
+		}
+
+		// the database records every result, including subdomains written out
+		// before a resume, each run being a scan of its own.
+		if p.database != nil {
+			if err := p.database.Add(domain, subdomain); err != nil {
+				hqgolog.Error().Msg(err.Error())
+			}
+		}
+
 		switch subdomain.Type {
 		case sources.ResultError:
+			// rejected candidates are not errors, their reasons are debug output.
+			var rejection *normalizer.RejectionError
+
+			if errors.As(subdomain.Error, &rejection) {
+				hqgolog.Debug().Msgf("%s: %s", subdomain.Source, rejection)
+
+				continue
+			}
+
+			p.statistics[subdomain.Source].errors++
+
 			if verbose {
 				hqgolog.Error().Msgf("%s: %s\n", subdomain.Source, subdomain.Error)
 			}
-		case sources.ResultSubdomain:
-			if verbose {
-				hqgolog.Print().Msgf("[%s] %s", au.BrightBlue(subdomain.Source), subdomain.Value)
+		case sources.ResultInfo:
+			hqgolog.Debug().Msgf("%s: %s", subdomain.Source, subdomain.Value)
+		case sources.ResultAttribution:
+			// attributions are only recorded in the database.
+			continue
+		case sources.ResultIP, sources.ResultCNAME:
+			// IP addresses and CNAMEs are only part of the JSON lines output.
+			if jsonLines {
+				write(writer, subdomain)
+			}
+		case sources.ResultRelated:
+			// related and root domains are outside the scope, they are only
+			// written out as JSON lines.
+			if jsonLines {
+				write(writer, subdomain)
 			} else {
+				related = append(related, subdomain)
+			}
+		case sources.ResultProbe:
+			if jsonLines {
+				write(writer, subdomain)
+			} else {
+				servers = append(servers, subdomain)
+			}
+		case sources.ResultTakeover:
+			if jsonLines {
+				write(writer, subdomain)
+			} else {
+				candidates = append(candidates, subdomain)
+			}
+		case sources.ResultRootDomain:
+			if jsonLines {
+				write(writer, subdomain)
+			} else {
+				siblings = append(siblings, subdomain)
+			}
+
+			p.progress.MarkRoot(domain, subdomain.Value)
+		case sources.ResultSubdomain:
+			// skip subdomains already written out before a resume.
+			if p.progress.IsWritten(domain, subdomain.Value) {
+				continue
+			}
+
+			p.statistics[subdomain.Source].subdomains++
+
+			hosts := map[string]struct{}{}
+
+			for _, address := range subdomain.Addresses {
+				if provider := network(address); provider != "" {
+					hosts[provider] = struct{}{}
+				}
+			}
+
+			for provider := range hosts {
+				providers[provider]++
+			}
+
+			switch {
+			case jsonLines:
+				write(writer, subdomain)
+			case verbose:
+				hqgolog.Print().Msgf("[%s] %s%s", au.BrightBlue(subdomain.Source), subdomain.Value, details(subdomain))
+
+				writeLine(writer, subdomain.Value)
+			default:
 				hqgolog.Print().Msg(subdomain.Value)
+
+				writeLine(writer, subdomain.Value)
 			}
 
-			if writer != nil {
-				fmt.Fprintln(writer, subdomain.Value)
+			if p.notifications != nil {
+				event := notifier.Event{
+					Domain:    domain,
+					Subdomain: subdomain.Value,
+					Source:    subdomain.Source,
+				}
 
-				if err := writer.Flush(); err != nil {
-					hqgolog.Fatal().Msg(err.Error())
+				if err := p.notifications.Notify(context.Background(), event); err != nil {
+					hqgolog.Error().Msg(err.Error())
 				}
 			}
+
+			p.progress.MarkWritten(domain, subdomain.Value)
+		}
+	}
+
+	if silent {
+		return
+	}
+
+	list(fmt.Sprintf("%d related domain(s) outside the scope of %v:", len(related), au.Underline(domain).Bold()), related)
+	list(fmt.Sprintf("%d sibling root domain(s) of %v:", len(siblings), au.Underline(domain).Bold()), siblings)
+	list(fmt.Sprintf("%d takeover candidate(s) of %v:", len(candidates), au.Underline(domain).Bold()), candidates)
+	list(fmt.Sprintf("%d web server(s) of %v:", len(servers), au.Underline(domain).Bold()), servers)
+	summarizeClusters(domain, servers)
+
+	if len(providers) > 0 && !jsonLines {
+		summarizeProviders(domain, providers)
+	}
+
+	return
+}
+
+// record is a result as output in JSON lines.
+type record struct {
+	Type      string     `json:"type"`
+	Source    string     `json:"source"`
+	Value     string     `json:"value"`
+	Subdomain string     `json:"subdomain,omitempty"`
+	Evidence  string     `json:"evidence,omitempty"`
+	FirstSeen *time.Time `json:"first_seen,omitempty"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+	Addresses []address  `json:"addresses,omitempty"`
+	Response  *response  `json:"response,omitempty"`
+	Cluster   *cluster   `json:"cluster,omitempty"`
+}
+
+// response is the response of a web server probed as output in JSON lines.
+type response struct {
+	StatusCode        int    `json:"status_code"`
+	Title             string `json:"title,omitempty"`
+	ContentLength     int64  `json:"content_length"`
+	Server            string `json:"server,omitempty"`
+	FinalURL          string `json:"final_url"`
+	SimHash           string `json:"simhash"`
+	HeaderFingerprint string `json:"header_fingerprint"`
+}
+
+// cluster is the cluster of similar responses of a web server probed as
+// output in JSON lines.
+type cluster struct {
+	ID             int    `json:"id"`
+	Size           int    `json:"size"`
+	Representative string `json:"representative"`
+}
+
+// address is an address of a subdomain as output in JSON lines.
+type address struct {
+	IP           string `json:"ip"`
+	ASN          uint   `json:"asn,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	Country      string `json:"country,omitempty"`
+	CIDR         string `json:"cidr,omitempty"`
+}
+
+// write displays result as a JSON line and writes it out, if writer is not nil.
+func write(writer *bufio.Writer, result sources.Result) {
+	r := record{
+		Type:      result.Type.String(),
+		Source:    result.Source,
+		Value:     result.Value,
+		Subdomain: result.Subdomain,
+		Evidence:  result.Evidence,
+	}
+
+	if !result.FirstSeen.IsZero() {
+		r.FirstSeen = &result.FirstSeen
+	}
+
+	if !result.LastSeen.IsZero() {
+		r.LastSeen = &result.LastSeen
+	}
+
+	for _, a := range result.Addresses {
+		r.Addresses = append(r.Addresses, address(a))
+	}
+
+	if result.Response != nil {
+		r.Response = &response{
+			StatusCode:    result.Response.StatusCode,
+			Title:         result.Response.Title,
+			ContentLength: result.Response.ContentLength,
+			Server:        result.Response.Server,
+			FinalURL:      result.Response.FinalURL,
+			// SimHashes are written in hex, 64-bit integers do not survive all JSON parsers.
+			SimHash:           fmt.Sprintf("%016x", result.Response.SimHash),
+			HeaderFingerprint: result.Response.HeaderFingerprint,
+		}
+	}
+
+	if result.Cluster != nil {
+		r.Cluster = (*cluster)(result.Cluster)
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		hqgolog.Error().Msg(err.Error())
+
+		return
+	}
+
+	hqgolog.Print().Msg(string(line))
+
+	writeLine(writer, string(line))
+}
+
+// writeLine writes line out, if writer is not nil.
+func writeLine(writer *bufio.Writer, line string) {
+	if writer == nil {
+		return
+	}
+
+	fmt.Fprintln(writer, line)
+
+	if err := writer.Flush(); err != nil {
+		hqgolog.Fatal().Msg(err.Error())
+	}
+}
+
+// summarizeProviders prints the number of subdomains of domain hosted per
+// autonomous system, most used first.
+func summarizeProviders(domain string, providers map[string]int) {
+	names := make([]string, 0, len(providers))
+
+	for name := range providers {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if providers[names[i]] != providers[names[j]] {
+			return providers[names[i]] > providers[names[j]]
+		}
+
+		return names[i] < names[j]
+	})
+
+	hqgolog.Print().Msg("")
+	hqgolog.Info().Msgf("%d hosting provider(s) of %v:", len(names), au.Underline(domain).Bold())
+	hqgolog.Print().Msg("")
+
+	for _, name := range names {
+		hqgolog.Print().Msgf("> %s: %d subdomain(s)", name, providers[name])
+	}
+}
+
+// summarizeClusters prints the clusters of similar responses of the web
+// servers of domain, if clustered, with their representative, largest first.
+func summarizeClusters(domain string, servers []sources.Result) {
+	clusters := map[int]*sources.Cluster{}
+
+	for _, server := range servers {
+		if server.Cluster != nil {
+			clusters[server.Cluster.ID] = server.Cluster
 		}
 	}
+
+	if len(clusters) == 0 {
+		return
+	}
+
+	hqgolog.Print().Msg("")
+	hqgolog.Info().Msgf("%d cluster(s) of similar web servers of %v:", len(clusters), au.Underline(domain).Bold())
+	hqgolog.Print().Msg("")
+
+	for id := 1; id <= len(clusters); id++ {
+		c, ok := clusters[id]
+		if !ok {
+			continue
+		}
+
+		hqgolog.Print().Msgf("> #%d: %s, %d web server(s)", c.ID, c.Representative, c.Size)
+	}
+}
+
+// list prints results, which are not written out, under title.
+func list(title string, results []sources.Result) {
+	if len(results) == 0 {
+		return
+	}
+
+	hqgolog.Print().Msg("")
+	hqgolog.Info().Msg(title)
+	hqgolog.Print().Msg("")
+
+	for _, result := range results {
+		switch {
+		case verbose:
+			hqgolog.Print().Msgf("[%s] %s%s", au.BrightBlue(result.Source), result.Value, details(result))
+		case result.Type == sources.ResultTakeover:
+			// takeover candidates are flagged with the fingerprint they match.
+			hqgolog.Print().Msgf("%s (%s)", result.Value, result.Evidence)
+		case result.Type == sources.ResultProbe && result.Cluster != nil:
+			hqgolog.Print().Msgf("%s%s [#%d]", result.Value, summary(result.Response), result.Cluster.ID)
+		case result.Type == sources.ResultProbe:
+			hqgolog.Print().Msgf("%s%s", result.Value, summary(result.Response))
+		default:
+			hqgolog.Print().Msg(result.Value)
+		}
+	}
+}
+
+// summary returns a one-line description of a probed response, e.g.
+// " [200] [Example Domain] [ECS] [1256] -> https://www.example.com/".
+func summary(response *sources.Response) string {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, " [%d]", response.StatusCode)
+
+	if response.Title != "" {
+		fmt.Fprintf(builder, " [%s]", response.Title)
+	}
+
+	if response.Server != "" {
+		fmt.Fprintf(builder, " [%s]", response.Server)
+	}
+
+	fmt.Fprintf(builder, " [%d]", response.ContentLength)
+
+	fmt.Fprintf(builder, " -> %s", response.FinalURL)
+
+	return builder.String()
+}
+
+// network returns the autonomous system of address, e.g. "AS13335 CLOUDFLARENET",
+// or an empty string if unknown.
+func network(address sources.Address) string {
+	if address.ASN == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("AS%d %s", address.ASN, address.Organization))
+}
+
+// details returns, for verbose output, where and when result was found, e.g.
+// " (https://example.com/, first seen 2021-03-04, last seen 2024-05-06)".
+func details(result sources.Result) string {
+	var parts []string
+
+	if result.Evidence != "" {
+		parts = append(parts, result.Evidence)
+	}
+
+	if !result.FirstSeen.IsZero() {
+		parts = append(parts, "first seen "+result.FirstSeen.Format(time.DateOnly))
+	}
+
+	if !result.LastSeen.IsZero() {
+		parts = append(parts, "last seen "+result.LastSeen.Format(time.DateOnly))
+	}
+
+	for _, address := range result.Addresses {
+		parts = append(parts, strings.TrimSpace(fmt.Sprintf("%s %s %s", address.IP, network(address), address.Country)))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// summarize prints the subdomains and errors counted per source so far.
+func (p *processor) summarize() {
+	names := make([]string, 0, len(p.statistics))
+
+	for name := range p.statistics {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	hqgolog.Print().Msg("")
+	hqgolog.Warn().Msgf("interrupted, partial summary (resume with %v):", au.Underline("--resume").Bold())
+	hqgolog.Print().Msg("")
+
+	total := 0
+
+	for _, name := range names {
+		stat := p.statistics[name]
+
+		total += stat.subdomains
+
+		hqgolog.Print().Msgf("> %s: %d subdomain(s), %d error(s)", name, stat.subdomains, stat.errors)
+	}
+
+	hqgolog.Print().Msg("")
+	hqgolog.Info().Msgf("%d subdomain(s) found before interruption.", total)
+}
+
+func closeOutput(writer *bufio.Writer, file *os.File) {
+	if err := writer.Flush(); err != nil {
+		hqgolog.Error().Msg(err.Error())
+	}
+
+	if err := file.Close(); err != nil {
+		hqgolog.Error().Msg(err.Error())
+	}
 }
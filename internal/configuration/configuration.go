@@ -6,15 +6,37 @@ import (
 
 	"dario.cat/mergo"
 	"github.com/hueristiq/hqgolog"
+	"github.com/hueristiq/xsubfind3r/pkg/notifier"
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/enrichment"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
 	"github.com/logrusorgru/aurora/v3"
 	"gopkg.in/yaml.v3"
 )
 
 type Configuration struct {
-	Version string       `yaml:"version"`
-	Sources []string     `yaml:"sources"`
-	Keys    sources.Keys `yaml:"keys"`
+	Version       string                           `yaml:"version"`
+	Sources       []string                         `yaml:"sources"`
+	Keys          sources.Keys                     `yaml:"keys"`
+	CTLog         sources.CTLogConfiguration       `yaml:"ctlog" mapstructure:"ctlog"`
+	GitLab        sources.GitLabConfiguration      `yaml:"gitlab" mapstructure:"gitlab"`
+	LocalFS       sources.LocalFSConfiguration     `yaml:"localfs" mapstructure:"localfs"`
+	Import        sources.ImportConfiguration      `yaml:"import" mapstructure:"import"`
+	PassiveDNS    sources.PassiveDNSConfiguration  `yaml:"passive_dns" mapstructure:"passive_dns"`
+	HostSearch    sources.HostSearchConfiguration  `yaml:"host_search" mapstructure:"host_search"`
+	Whois         sources.WhoisConfiguration       `yaml:"whois" mapstructure:"whois"`
+	Resolvers     []string                         `yaml:"resolvers" mapstructure:"resolvers"`
+	Retries       int                              `yaml:"resolver_retries" mapstructure:"resolver_retries"`
+	Bruteforce    sources.BruteforceConfiguration  `yaml:"bruteforce" mapstructure:"bruteforce"`
+	Permutation   sources.PermutationConfiguration `yaml:"permutation" mapstructure:"permutation"`
+	ZoneWalk      sources.ZoneWalkConfiguration    `yaml:"zonewalk" mapstructure:"zonewalk"`
+	DNSRecords    sources.DNSRecordsConfiguration  `yaml:"dnsrecords" mapstructure:"dnsrecords"`
+	TLSGrab       sources.TLSGrabConfiguration     `yaml:"tlsgrab" mapstructure:"tlsgrab"`
+	Crawl         sources.CrawlConfiguration       `yaml:"crawl" mapstructure:"crawl"`
+	Takeover      sources.TakeoverConfiguration    `yaml:"takeover" mapstructure:"takeover"`
+	Enrichment    enrichment.Configuration         `yaml:"enrichment" mapstructure:"enrichment"`
+	Probe         sources.ProbeConfiguration       `yaml:"probe" mapstructure:"probe"`
+	Notifications notifier.Configuration           `yaml:"notifications" mapstructure:"notifications"`
 }
 
 func (cfg *Configuration) Write(path string) (err error) {
@@ -75,11 +97,15 @@ __  _____ _   _| |__  / _(_)_ __   __| |___ / _ __
 	ProjectRootDirectoryPath = filepath.Join(UserDotConfigDirectoryPath, projectRootDirectoryName)
 	configurationFileName    = "config.yaml"
 	ConfigurationFilePath    = filepath.Join(ProjectRootDirectoryPath, configurationFileName)
+	checkpointsDirectoryName = "checkpoints"
+	CheckpointsDirectoryPath = filepath.Join(ProjectRootDirectoryPath, checkpointsDirectoryName)
 )
 
 func CreateUpdate(path string) (err error) {
 	var cfg Configuration
 
+	maxRedirects, maxDistance := 5, 6
+
 	defaultConfig := Configuration{
 		Version: VERSION,
 		Sources: sources.List,
@@ -88,13 +114,127 @@ func CreateUpdate(path string) (err error) {
 			BuiltWith:      []string{},
 			Censys:         []string{},
 			Chaos:          []string{},
+			CIRCL:          []string{},
+			DNSDB:          []string{},
+			FOFA:           []string{},
 			Fullhunt:       []string{},
 			GitHub:         []string{},
+			GitLab:         []string{},
+			Hunter:         []string{},
 			Intelx:         []string{},
+			Mnemonic:       []string{},
+			Netlas:         []string{},
 			SecurityTrails: []string{},
 			Shodan:         []string{},
 			URLScan:        []string{},
 			VirusTotal:     []string{},
+			WhoisXML:       []string{},
+			ZoomEye:        []string{},
+		},
+		CTLog: sources.CTLogConfiguration{
+			Logs: []string{
+				"https://ct.googleapis.com/logs/us1/argon2026h2/",
+				"https://ct.googleapis.com/logs/eu1/xenon2026h2/",
+				"https://ct.cloudflare.com/logs/nimbus2026/",
+			},
+			MaxEntries:     10000,
+			CacheDirectory: filepath.Join(ProjectRootDirectoryPath, "ctlog"),
+			MaxCachedNames: 1000000,
+		},
+		GitLab: sources.GitLabConfiguration{
+			BaseURL: "https://gitlab.com",
+		},
+		LocalFS: sources.LocalFSConfiguration{
+			Paths:   []string{},
+			MaxSize: 5 << 20,
+		},
+		Import: sources.ImportConfiguration{
+			Files: []string{},
+		},
+		PassiveDNS: sources.PassiveDNSConfiguration{
+			MaxPages: 10,
+		},
+		HostSearch: sources.HostSearchConfiguration{
+			MaxPages: 10,
+		},
+		Whois: sources.WhoisConfiguration{
+			MaxDomains: 100,
+		},
+		Resolvers: resolver.DefaultServers,
+		Retries:   resolver.DefaultRetries,
+		Bruteforce: sources.BruteforceConfiguration{
+			Concurrency: 50,
+		},
+		Permutation: sources.PermutationConfiguration{
+			Words:         []string{"dev", "stage", "staging", "test", "qa", "uat", "prod", "api", "admin", "internal"},
+			MaxTokens:     20,
+			MaxCandidates: 10000,
+			Concurrency:   50,
+		},
+		ZoneWalk: sources.ZoneWalkConfiguration{
+			Nameservers:  []string{},
+			MaxNames:     10000,
+			NSEC3Queries: 200,
+			HashesFile:   filepath.Join(ProjectRootDirectoryPath, "nsec3.hashes"),
+		},
+		DNSRecords: sources.DNSRecordsConfiguration{
+			SRVServices: []string{
+				"_sip._tcp",
+				"_sip._udp",
+				"_sips._tcp",
+				"_autodiscover._tcp",
+				"_xmpp-client._tcp",
+				"_xmpp-server._tcp",
+				"_caldav._tcp",
+				"_carddav._tcp",
+				"_imaps._tcp",
+				"_submission._tcp",
+				"_ldap._tcp",
+				"_kerberos._tcp",
+			},
+			MaxNames:    1000,
+			Concurrency: 20,
+		},
+		TLSGrab: sources.TLSGrabConfiguration{
+			Ports:       []int{443, 8443},
+			MaxHosts:    1000,
+			Concurrency: 25,
+			Timeout:     "5s",
+		},
+		Crawl: sources.CrawlConfiguration{
+			MaxHosts:    500,
+			MaxDepth:    2,
+			MaxPages:    20,
+			MaxSize:     2 << 20,
+			Concurrency: 10,
+			Timeout:     "10s",
+		},
+		Takeover: sources.TakeoverConfiguration{
+			MaxHosts:    1000,
+			Concurrency: 25,
+			Timeout:     "10s",
+		},
+		Enrichment: enrichment.Configuration{
+			Databases:   []string{},
+			ExcludeASN:  []string{},
+			Concurrency: 50,
+		},
+		Probe: sources.ProbeConfiguration{
+			HTTPPorts:    []int{80, 8080},
+			HTTPSPorts:   []int{443, 8443},
+			MaxRedirects: &maxRedirects,
+			MaxSize:      1 << 20,
+			Concurrency:  50,
+			Timeout:      "10s",
+			MaxDistance:  &maxDistance,
+		},
+		Notifications: notifier.Configuration{
+			BatchSize:     20,
+			MaxRetries:    3,
+			QueueSize:     1000,
+			Timeout:       "10s",
+			KnownFilePath: filepath.Join(ProjectRootDirectoryPath, "known.txt"),
+			Webhooks:      []notifier.Webhook{},
 		},
 	}
 
@@ -116,7 +256,7 @@ func CreateUpdate(path string) (err error) {
 		}
 
 		if cfg.Version != VERSION || len(cfg.Sources) != len(sources.List) {
-			if err = mergo.Merge(&cfg, defaultConfig); err != nil {
+			if err = mergo.Merge(&cfg, defaultConfig, mergo.WithoutDereference); err != nil {
 				return
 			}
 
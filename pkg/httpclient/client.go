@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -40,8 +41,9 @@ func httpRequestWrapper(req *hqgohttp.Request) (res *http.Response, err error) {
 }
 
 // HTTPRequest makes any HTTP request to a URL with extended parameters.
-func HTTPRequest(method, requestURL, cookies string, headers map[string]string, body io.Reader) (*http.Response, error) {
-	req, err := hqgohttp.NewRequest(method, requestURL, body)
+// The request is aborted as soon as ctx is canceled.
+func HTTPRequest(ctx context.Context, method, requestURL, cookies string, headers map[string]string, body io.Reader) (*http.Response, error) {
+	req, err := hqgohttp.NewRequestWithContext(ctx, method, requestURL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -59,22 +61,48 @@ func HTTPRequest(method, requestURL, cookies string, headers map[string]string,
 		req.Header.Set(key, value)
 	}
 
-	return httpRequestWrapper(req)
+	// The client retries failed attempts with a backoff of its own, which does
+	// not observe ctx. Waiting in a goroutine lets cancellation return at once.
+	type response struct {
+		res *http.Response
+		err error
+	}
+
+	done := make(chan response, 1)
+
+	go func() {
+		res, err := httpRequestWrapper(req)
+
+		done <- response{res, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		go func() {
+			r := <-done
+
+			DiscardResponse(r.res)
+		}()
+
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.res, r.err
+	}
 }
 
 // Get makes a GET request to a URL with extended parameters.
-func Get(URL, cookies string, headers map[string]string) (*http.Response, error) {
-	return HTTPRequest(methods.Get, URL, cookies, headers, nil)
+func Get(ctx context.Context, URL, cookies string, headers map[string]string) (*http.Response, error) {
+	return HTTPRequest(ctx, methods.Get, URL, cookies, headers, nil)
 }
 
 // SimpleGet makes a simple GET request to a URL.
-func SimpleGet(URL string) (*http.Response, error) {
-	return HTTPRequest(methods.Get, URL, "", map[string]string{}, nil)
+func SimpleGet(ctx context.Context, URL string) (*http.Response, error) {
+	return HTTPRequest(ctx, methods.Get, URL, "", map[string]string{}, nil)
 }
 
 // Post makes a POST request to a URL with extended parameters.
-func Post(URL, cookies string, headers map[string]string, body io.Reader) (*http.Response, error) {
-	return HTTPRequest(methods.Post, URL, cookies, headers, body)
+func Post(ctx context.Context, URL, cookies string, headers map[string]string, body io.Reader) (*http.Response, error) {
+	return HTTPRequest(ctx, methods.Post, URL, cookies, headers, body)
 }
 
 func DiscardResponse(response *http.Response) {
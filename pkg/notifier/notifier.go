@@ -0,0 +1,591 @@
+package notifier
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	retrier "github.com/hueristiq/hq-go-retrier"
+	"github.com/hueristiq/hq-go-retrier/backoff"
+)
+
+// Notifier batches findings and delivers them to the configured webhooks.
+// Findings are queued and delivered in the background, so slow webhooks do
+// not hold callers up. It is safe for concurrent use and can be shared across
+// many runs, which makes it usable both from one-off runs and from
+// long-running monitors.
+type Notifier struct {
+	client *http.Client
+
+	webhooks []*webhook
+
+	batchSize  int
+	maxRetries int
+	// maxPending caps the events pending per webhook, those of webhooks that
+	// keep failing, past which the oldest are dropped.
+	maxPending int
+
+	// queue feeds the goroutine delivering events, stopped is closed once it
+	// returned.
+	queue   chan request
+	stopped chan struct{}
+
+	// known holds every subdomain delivered, it backs the `only_new` filter.
+	known         map[string]struct{}
+	knownFilePath string
+	knownNew      []string
+	// undelivered counts, per subdomain queued, the webhooks yet to deliver
+	// it: it becomes known once all did.
+	undelivered map[string]int
+
+	// errs are the delivery errors since the last flush.
+	errs []error
+}
+
+// request is an event to queue, or, if flush is set, a request to deliver
+// all pending events, answered on flush.
+type request struct {
+	ctx   context.Context
+	event Event
+	flush chan error
+}
+
+// Configuration holds the notification settings loaded from `config.yaml`.
+type Configuration struct {
+	// BatchSize is the number of events sent in a single webhook request.
+	BatchSize int `yaml:"batch_size" mapstructure:"batch_size"`
+	// MaxRetries is the number of attempts made to deliver a batch.
+	MaxRetries int `yaml:"max_retries" mapstructure:"max_retries"`
+	// QueueSize is the number of events queued for delivery at most, past
+	// which Notify waits. It also caps the events held for a failing webhook,
+	// past which the oldest are dropped.
+	QueueSize int `yaml:"queue_size" mapstructure:"queue_size"`
+	// Timeout is the per-request timeout, e.g. "10s".
+	Timeout string `yaml:"timeout" mapstructure:"timeout"`
+	// KnownFilePath is the file in which subdomains already seen are persisted.
+	KnownFilePath string `yaml:"known_file" mapstructure:"known_file"`
+	// Webhooks are the notification targets.
+	Webhooks []Webhook `yaml:"webhooks" mapstructure:"webhooks"`
+}
+
+// Webhook describes a single notification target.
+type Webhook struct {
+	// Name identifies the webhook in error messages.
+	Name string `yaml:"name" mapstructure:"name"`
+	// Type is one of `slack`, `discord` or `json`, it selects the default template.
+	Type string `yaml:"type" mapstructure:"type"`
+	// URL is the webhook endpoint.
+	URL string `yaml:"url" mapstructure:"url"`
+	// Headers are extra HTTP headers sent with each request.
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"`
+	// Template is a Go text/template rendering the request body from a Batch.
+	Template string `yaml:"template" mapstructure:"template"`
+	// Match, if set, is a regular expression subdomains must match to be notified.
+	Match string `yaml:"match" mapstructure:"match"`
+	// OnlyNew restricts notifications to subdomains not seen in previous runs.
+	OnlyNew bool `yaml:"only_new" mapstructure:"only_new"`
+}
+
+// Event is a single finding.
+type Event struct {
+	Domain    string    `json:"domain"`
+	Subdomain string    `json:"subdomain"`
+	Source    string    `json:"source"`
+	Time      time.Time `json:"time"`
+}
+
+// Batch is the data a webhook template is rendered with.
+type Batch struct {
+	Webhook string  `json:"webhook"`
+	Events  []Event `json:"events"`
+	// Text is a plain-text summary of the batch, handy for chat webhooks.
+	Text string `json:"text"`
+}
+
+type webhook struct {
+	Webhook
+
+	match    *regexp.Regexp
+	template *template.Template
+	pending  []Event
+	// failed is set when the last delivery failed, batches are then held
+	// until the next flush instead of retried on every event.
+	failed bool
+	// dropped counts the events dropped since the last flush, pending events
+	// being capped.
+	dropped int
+}
+
+// Types of webhooks with a built-in default template.
+const (
+	TypeSlack   = "slack"
+	TypeDiscord = "discord"
+	TypeJSON    = "json"
+)
+
+var (
+	defaultTemplates = map[string]string{
+		TypeSlack:   `{"text":{{ json .Text }}}`,
+		TypeDiscord: `{"content":{{ json .Text }}}`,
+		TypeJSON:    `{{ json . }}`,
+	}
+
+	templateFuncs = template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			raw, err := json.Marshal(v)
+
+			return string(raw), err
+		},
+	}
+
+	ErrUnknownWebhookType = errors.New("unknown webhook type")
+	ErrUnexpectedStatus   = errors.New("unexpected status code")
+	ErrEventsDropped      = errors.New("events dropped")
+)
+
+// Notify queues a finding for delivery to every webhook whose filters accept
+// it. It only waits, until ctx is canceled, when the queue is full.
+// Delivery errors are returned by the next Flush.
+func (n *Notifier) Notify(ctx context.Context, event Event) (err error) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	select {
+	case n.queue <- request{ctx: ctx, event: event}:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	return
+}
+
+// Flush delivers all pending events, persists newly delivered subdomains and
+// returns the delivery errors since the last flush. Events whose delivery
+// failed stay pending, for the next flush, those refused by the webhook are
+// dropped.
+func (n *Notifier) Flush(ctx context.Context) (err error) {
+	done := make(chan error, 1)
+
+	select {
+	case n.queue <- request{ctx: ctx, flush: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	return
+}
+
+// Close stops delivering events, once those queued are. Events pending in
+// batches are not delivered, Flush first to deliver them.
+func (n *Notifier) Close() {
+	close(n.queue)
+
+	<-n.stopped
+}
+
+// deliver handles the requests queued, until the queue is closed.
+func (n *Notifier) deliver() {
+	defer close(n.stopped)
+
+	for req := range n.queue {
+		if req.flush != nil {
+			req.flush <- n.flush(req.ctx)
+
+			continue
+		}
+
+		n.add(req.ctx, req.event)
+	}
+}
+
+// add adds event to the pending events of every webhook whose filters accept
+// it, delivering batches that reach the configured size.
+func (n *Notifier) add(ctx context.Context, event Event) {
+	_, seen := n.known[event.Subdomain]
+
+	for _, w := range n.webhooks {
+		if w.OnlyNew && seen {
+			continue
+		}
+
+		if w.match != nil && !w.match.MatchString(event.Subdomain) {
+			continue
+		}
+
+		w.pending = append(w.pending, event)
+
+		n.undelivered[event.Subdomain]++
+
+		if excess := len(w.pending) - n.maxPending; excess > 0 {
+			n.dropped(w.pending[:excess])
+
+			w.pending = w.pending[excess:]
+			w.dropped += excess
+		}
+
+		// Webhooks that failed are only retried on flush.
+		if len(w.pending) >= n.batchSize && !w.failed {
+			if err := n.send(ctx, w); err != nil {
+				n.errs = append(n.errs, err)
+			}
+		}
+	}
+}
+
+// flush delivers the pending events of all webhooks, persists the subdomains
+// delivered, and returns the delivery errors since the last flush.
+func (n *Notifier) flush(ctx context.Context) (err error) {
+	errs := n.errs
+
+	n.errs = nil
+
+	for _, w := range n.webhooks {
+		if w.dropped > 0 {
+			errs = append(errs, fmt.Errorf("webhook %s: %w: %d, over the queue size", w.Name, ErrEventsDropped, w.dropped))
+
+			w.dropped = 0
+		}
+
+		if len(w.pending) == 0 {
+			continue
+		}
+
+		if err := n.send(ctx, w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := n.saveKnown(); err != nil {
+		errs = append(errs, err)
+	}
+
+	err = errors.Join(errs...)
+
+	return
+}
+
+// delivered records that a webhook delivered events, their subdomains
+// becoming known once delivered by all the webhooks they were queued for.
+func (n *Notifier) delivered(events []Event) {
+	for _, event := range events {
+		n.undelivered[event.Subdomain]--
+
+		if n.undelivered[event.Subdomain] > 0 {
+			continue
+		}
+
+		delete(n.undelivered, event.Subdomain)
+
+		if _, ok := n.known[event.Subdomain]; ok {
+			continue
+		}
+
+		n.known[event.Subdomain] = struct{}{}
+		n.knownNew = append(n.knownNew, event.Subdomain)
+	}
+}
+
+// dropped records that events will not be delivered, their subdomains are
+// not made known.
+func (n *Notifier) dropped(events []Event) {
+	for _, event := range events {
+		n.undelivered[event.Subdomain]--
+
+		if n.undelivered[event.Subdomain] <= 0 {
+			delete(n.undelivered, event.Subdomain)
+		}
+	}
+}
+
+// send delivers the pending events of w, in batches of the configured size.
+// Events are only removed from the pending events once delivered, or once
+// refused by the webhook, e.g. with a 400 for a body it cannot parse, as
+// sending them again would fail again. Delivery stops at the first batch that
+// fails.
+func (n *Notifier) send(ctx context.Context, w *webhook) (err error) {
+	for len(w.pending) > 0 {
+		events := w.pending[:min(n.batchSize, len(w.pending))]
+
+		var refused bool
+
+		refused, err = n.post(ctx, w, events)
+
+		switch {
+		case err == nil:
+			n.delivered(events)
+		case refused:
+			n.dropped(events)
+		default:
+			w.failed = true
+
+			return
+		}
+
+		w.pending = w.pending[len(events):]
+
+		if refused {
+			return
+		}
+	}
+
+	w.pending = nil
+	w.failed = false
+
+	return
+}
+
+// post sends events to w in a batch. Transport errors, 429 and 5xx responses
+// are retried, other failures are not and are reported as refused.
+func (n *Notifier) post(ctx context.Context, w *webhook, events []Event) (refused bool, err error) {
+	lines := make([]string, 0, len(events)+1)
+
+	lines = append(lines, fmt.Sprintf("%d new finding(s):", len(events)))
+
+	for _, event := range events {
+		lines = append(lines, fmt.Sprintf("%s [%s]", event.Subdomain, event.Source))
+	}
+
+	batch := Batch{
+		Webhook: w.Name,
+		Events:  events,
+		Text:    strings.Join(lines, "\n"),
+	}
+
+	body := &bytes.Buffer{}
+
+	if err = w.template.Execute(body, batch); err != nil {
+		refused = true
+
+		err = fmt.Errorf("webhook %s: %w", w.Name, err)
+
+		return
+	}
+
+	payload := body.Bytes()
+
+	// permanent is the failure that retrying cannot fix, it stops retries.
+	var permanent error
+
+	err = retrier.Retry(ctx, func() (err error) {
+		var req *http.Request
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+		if err != nil {
+			return
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		for key, value := range w.Headers {
+			req.Header.Set(key, value)
+		}
+
+		var res *http.Response
+
+		res, err = n.client.Do(req)
+		if err != nil {
+			return
+		}
+
+		res.Body.Close()
+
+		switch {
+		case res.StatusCode >= 200 && res.StatusCode <= 299:
+		case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+			err = fmt.Errorf("%w: %d", ErrUnexpectedStatus, res.StatusCode)
+		default:
+			permanent = fmt.Errorf("%w: %d", ErrUnexpectedStatus, res.StatusCode)
+		}
+
+		return
+	},
+		retrier.WithMaxRetries(n.maxRetries),
+		retrier.WithMinDelay(1*time.Second),
+		retrier.WithMaxDelay(30*time.Second),
+		retrier.WithBackoff(backoff.ExponentialWithFullJitter()),
+	)
+	if err == nil && permanent != nil {
+		refused = true
+
+		err = permanent
+	}
+
+	if err != nil {
+		err = fmt.Errorf("webhook %s: %w", w.Name, err)
+	}
+
+	return
+}
+
+func (n *Notifier) loadKnown() (err error) {
+	if n.knownFilePath == "" {
+		return
+	}
+
+	var file *os.File
+
+	file, err = os.Open(n.knownFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+
+		return
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		subdomain := scanner.Text()
+
+		if subdomain != "" {
+			n.known[subdomain] = struct{}{}
+		}
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+func (n *Notifier) saveKnown() (err error) {
+	if n.knownFilePath == "" || len(n.knownNew) == 0 {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(n.knownFilePath), os.ModePerm); err != nil {
+		return
+	}
+
+	var file *os.File
+
+	file, err = os.OpenFile(n.knownFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	for _, subdomain := range n.knownNew {
+		fmt.Fprintln(writer, subdomain)
+	}
+
+	if err = writer.Flush(); err != nil {
+		return
+	}
+
+	n.knownNew = nil
+
+	return
+}
+
+// New creates a Notifier from the given configuration, compiling each
+// webhook's filter and template and loading the known subdomains file.
+func New(cfg *Configuration) (n *Notifier, err error) {
+	n = &Notifier{
+		client:        &http.Client{Timeout: 10 * time.Second},
+		batchSize:     cfg.BatchSize,
+		maxRetries:    cfg.MaxRetries,
+		known:         map[string]struct{}{},
+		knownFilePath: cfg.KnownFilePath,
+		undelivered:   map[string]int{},
+	}
+
+	queueSize := cfg.QueueSize
+
+	if queueSize < 1 {
+		queueSize = 1000
+	}
+
+	n.maxPending = queueSize
+
+	if n.batchSize < 1 {
+		n.batchSize = 20
+	}
+
+	if n.maxRetries < 1 {
+		n.maxRetries = 3
+	}
+
+	if cfg.Timeout != "" {
+		var timeout time.Duration
+
+		timeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return
+		}
+
+		n.client.Timeout = timeout
+	}
+
+	for index := range cfg.Webhooks {
+		w := &webhook{Webhook: cfg.Webhooks[index]}
+
+		if w.Name == "" {
+			w.Name = w.URL
+		}
+
+		text := w.Template
+
+		if text == "" {
+			var ok bool
+
+			text, ok = defaultTemplates[w.Type]
+			if !ok {
+				err = fmt.Errorf("webhook %s: %w: %q", w.Name, ErrUnknownWebhookType, w.Type)
+
+				return
+			}
+		}
+
+		w.template, err = template.New(w.Name).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			err = fmt.Errorf("webhook %s: %w", w.Name, err)
+
+			return
+		}
+
+		if w.Match != "" {
+			w.match, err = regexp.Compile(w.Match)
+			if err != nil {
+				err = fmt.Errorf("webhook %s: %w", w.Name, err)
+
+				return
+			}
+		}
+
+		n.webhooks = append(n.webhooks, w)
+	}
+
+	if err = n.loadKnown(); err != nil {
+		return
+	}
+
+	n.queue = make(chan request, queueSize)
+	n.stopped = make(chan struct{})
+
+	go n.deliver()
+
+	return
+}
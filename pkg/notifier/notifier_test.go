@@ -0,0 +1,346 @@
+package notifier_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/notifier"
+)
+
+// webhookServer records the batches posted to it, failing while fail is set,
+// with status if set or else with a 500.
+type webhookServer struct {
+	*httptest.Server
+
+	mutex   sync.Mutex
+	batches [][]string
+
+	fail     atomic.Bool
+	status   atomic.Int32
+	requests atomic.Int32
+}
+
+func newWebhookServer(t *testing.T) (server *webhookServer) {
+	t.Helper()
+
+	server = &webhookServer{}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.requests.Add(1)
+
+		if server.fail.Load() {
+			status := int(server.status.Load())
+
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+
+			w.WriteHeader(status)
+
+			return
+		}
+
+		var batch notifier.Batch
+
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("decoding batch: %v", err)
+		}
+
+		subdomains := make([]string, 0, len(batch.Events))
+
+		for _, event := range batch.Events {
+			subdomains = append(subdomains, event.Subdomain)
+		}
+
+		server.mutex.Lock()
+		server.batches = append(server.batches, subdomains)
+		server.mutex.Unlock()
+	}))
+
+	t.Cleanup(server.Close)
+
+	return
+}
+
+func (server *webhookServer) received() (batches [][]string) {
+	server.mutex.Lock()
+
+	defer server.mutex.Unlock()
+
+	return append(batches, server.batches...)
+}
+
+func newNotifier(t *testing.T, cfg *notifier.Configuration) (n *notifier.Notifier) {
+	t.Helper()
+
+	n, err := notifier.New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	t.Cleanup(n.Close)
+
+	return
+}
+
+func notify(t *testing.T, n *notifier.Notifier, subdomains ...string) {
+	t.Helper()
+
+	for _, subdomain := range subdomains {
+		event := notifier.Event{
+			Domain:    "example.com",
+			Subdomain: subdomain,
+			Source:    "test",
+		}
+
+		if err := n.Notify(context.Background(), event); err != nil {
+			t.Fatalf("Notify(%s) error = %v", subdomain, err)
+		}
+	}
+}
+
+func TestNotifierBatches(t *testing.T) {
+	server := newWebhookServer(t)
+
+	n := newNotifier(t, &notifier.Configuration{
+		BatchSize: 2,
+		Webhooks: []notifier.Webhook{
+			{Name: "test", Type: notifier.TypeJSON, URL: server.URL},
+		},
+	})
+
+	notify(t, n, "a.example.com", "b.example.com", "c.example.com")
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	got := server.received()
+
+	want := [][]string{{"a.example.com", "b.example.com"}, {"c.example.com"}}
+
+	if len(got) != len(want) {
+		t.Fatalf("batches = %v, want %v", got, want)
+	}
+
+	for index := range want {
+		if strings.Join(got[index], ",") != strings.Join(want[index], ",") {
+			t.Errorf("batch %d = %v, want %v", index, got[index], want[index])
+		}
+	}
+}
+
+func TestNotifierOnlyNew(t *testing.T) {
+	server := newWebhookServer(t)
+
+	known := filepath.Join(t.TempDir(), "known.txt")
+
+	if err := os.WriteFile(known, []byte("a.example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &notifier.Configuration{
+		KnownFilePath: known,
+		Webhooks: []notifier.Webhook{
+			{Name: "test", Type: notifier.TypeJSON, URL: server.URL, OnlyNew: true},
+		},
+	}
+
+	n := newNotifier(t, cfg)
+
+	notify(t, n, "a.example.com", "b.example.com")
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	got := server.received()
+
+	if len(got) != 1 || strings.Join(got[0], ",") != "b.example.com" {
+		t.Fatalf("batches = %v, want [[b.example.com]]", got)
+	}
+
+	// A later run knows the subdomains delivered.
+	n = newNotifier(t, cfg)
+
+	notify(t, n, "b.example.com")
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if got := server.received(); len(got) != 1 {
+		t.Errorf("batches = %v, want b.example.com not notified again", got)
+	}
+}
+
+func TestNotifierRetriesFailedBatches(t *testing.T) {
+	server := newWebhookServer(t)
+
+	server.fail.Store(true)
+
+	known := filepath.Join(t.TempDir(), "known.txt")
+
+	n := newNotifier(t, &notifier.Configuration{
+		MaxRetries:    1,
+		KnownFilePath: known,
+		Webhooks: []notifier.Webhook{
+			{Name: "test", Type: notifier.TypeJSON, URL: server.URL, OnlyNew: true},
+		},
+	})
+
+	notify(t, n, "a.example.com")
+
+	if err := n.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() error = nil, want the delivery error")
+	}
+
+	if server.requests.Load() < 1 {
+		t.Fatal("no delivery attempted")
+	}
+
+	// Undelivered subdomains are not known.
+	if _, err := os.Stat(known); !os.IsNotExist(err) {
+		t.Errorf("known file written before delivery, stat error = %v", err)
+	}
+
+	server.fail.Store(false)
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	got := server.received()
+
+	if len(got) != 1 || strings.Join(got[0], ",") != "a.example.com" {
+		t.Fatalf("batches = %v, want the failed batch delivered", got)
+	}
+
+	data, err := os.ReadFile(known)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "a.example.com\n" {
+		t.Errorf("known file = %q, want a.example.com", data)
+	}
+}
+
+func TestNotifierSendsFailedEventsInBatches(t *testing.T) {
+	server := newWebhookServer(t)
+
+	server.fail.Store(true)
+
+	n := newNotifier(t, &notifier.Configuration{
+		BatchSize:  2,
+		MaxRetries: 1,
+		Webhooks: []notifier.Webhook{
+			{Name: "test", Type: notifier.TypeJSON, URL: server.URL},
+		},
+	})
+
+	notify(t, n, "a.example.com", "b.example.com", "c.example.com", "d.example.com", "e.example.com")
+
+	if err := n.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() error = nil, want the delivery error")
+	}
+
+	server.fail.Store(false)
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	got := server.received()
+
+	want := [][]string{{"a.example.com", "b.example.com"}, {"c.example.com", "d.example.com"}, {"e.example.com"}}
+
+	if len(got) != len(want) {
+		t.Fatalf("batches = %v, want %v", got, want)
+	}
+
+	for index := range want {
+		if strings.Join(got[index], ",") != strings.Join(want[index], ",") {
+			t.Errorf("batch %d = %v, want %v", index, got[index], want[index])
+		}
+	}
+}
+
+func TestNotifierDoesNotRetryRefusedBatches(t *testing.T) {
+	server := newWebhookServer(t)
+
+	server.fail.Store(true)
+	server.status.Store(http.StatusBadRequest)
+
+	n := newNotifier(t, &notifier.Configuration{
+		MaxRetries: 3,
+		Webhooks: []notifier.Webhook{
+			{Name: "test", Type: notifier.TypeJSON, URL: server.URL},
+		},
+	})
+
+	notify(t, n, "a.example.com")
+
+	if err := n.Flush(context.Background()); !errors.Is(err, notifier.ErrUnexpectedStatus) {
+		t.Fatalf("Flush() error = %v, want ErrUnexpectedStatus", err)
+	}
+
+	if requests := server.requests.Load(); requests != 1 {
+		t.Errorf("requests = %d, want 1, a 400 is not retried", requests)
+	}
+
+	// The refused batch is dropped, not sent again.
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if requests := server.requests.Load(); requests != 1 {
+		t.Errorf("requests = %d, want the refused batch dropped", requests)
+	}
+}
+
+func TestNotifierCapsPendingEvents(t *testing.T) {
+	server := newWebhookServer(t)
+
+	server.fail.Store(true)
+
+	n := newNotifier(t, &notifier.Configuration{
+		BatchSize:  1,
+		MaxRetries: 1,
+		QueueSize:  2,
+		Webhooks: []notifier.Webhook{
+			{Name: "test", Type: notifier.TypeJSON, URL: server.URL},
+		},
+	})
+
+	notify(t, n, "a.example.com", "b.example.com", "c.example.com")
+
+	if err := n.Flush(context.Background()); !errors.Is(err, notifier.ErrEventsDropped) {
+		t.Fatalf("Flush() error = %v, want ErrEventsDropped", err)
+	}
+
+	server.fail.Store(false)
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	var got []string
+
+	for _, batch := range server.received() {
+		got = append(got, batch...)
+	}
+
+	if want := "b.example.com,c.example.com"; strings.Join(got, ",") != want {
+		t.Errorf("delivered = %v, want the newest %s", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package resolver
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Failures counts failed lookups, so that runs making many of them, most
+// timing out, report them once. It is safe for concurrent use.
+type Failures struct {
+	mutex sync.Mutex
+
+	count int
+	first error
+}
+
+// Add counts the failure err.
+func (f *Failures) Add(err error) {
+	f.mutex.Lock()
+
+	defer f.mutex.Unlock()
+
+	if f.first == nil {
+		f.first = err
+	}
+
+	f.count++
+}
+
+// Err returns an error telling how many lookups failed, wrapping the first
+// failure, or nil if none did.
+func (f *Failures) Err() (err error) {
+	f.mutex.Lock()
+
+	defer f.mutex.Unlock()
+
+	if f.count == 0 {
+		return
+	}
+
+	err = fmt.Errorf("%d lookup(s) failed, first error: %w", f.count, f.first)
+
+	return
+}
@@ -0,0 +1,423 @@
+package resolver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Resolver is a minimal stub resolver sending queries to a set of recursive
+// resolvers, in a round-robin fashion. Queries go over UDP and are retried over
+// TCP when the answer is truncated; failed queries are retried on the next
+// resolver. It is safe for concurrent use.
+type Resolver struct {
+	servers []string
+	next    atomic.Uint64
+
+	timeout time.Duration
+	retries int
+}
+
+// Configuration holds the options of a Resolver.
+type Configuration struct {
+	// Servers are the resolvers queried, as `ip` or `ip:port`.
+	Servers []string
+	// Timeout is the per-query timeout.
+	Timeout time.Duration
+	// Retries is the number of additional attempts made for a failed query,
+	// none if 0. DefaultRetries is the number used by default.
+	Retries int
+}
+
+const (
+	// udpPayloadSize is the EDNS(0) UDP payload size advertised, as
+	// recommended by DNS Flag Day 2020 to avoid IP fragmentation.
+	udpPayloadSize = 1232
+	// ednsDNSSECOK is the DO bit, in the TTL field of the OPT record (RFC 3225).
+	ednsDNSSECOK = 1 << 15
+
+	defaultTimeout = 3 * time.Second
+
+	// DefaultRetries is the number of retries used by default.
+	DefaultRetries = 2
+)
+
+var (
+	// DefaultServers are the resolvers used when none are configured.
+	DefaultServers = []string{
+		"1.1.1.1",
+		"1.0.0.1",
+		"8.8.8.8",
+		"8.8.4.4",
+		"9.9.9.9",
+		"149.112.112.112",
+	}
+
+	ErrServerFailure = errors.New("server failure")
+	ErrIDMismatch    = errors.New("response id mismatch")
+)
+
+// Query sends a query for name and qtype, and returns the answer. A negative
+// answer, e.g. NXDOMAIN, is an answer: only failures to obtain one are errors.
+func (r *Resolver) Query(ctx context.Context, name string, qtype dnsmessage.Type) (msg *dnsmessage.Message, err error) {
+	question := dnsmessage.Question{
+		Type:  qtype,
+		Class: dnsmessage.ClassINET,
+	}
+
+	question.Name, err = dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return
+	}
+
+	for attempt := 0; attempt <= r.retries; attempt++ {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+
+			return
+		}
+
+		server := r.servers[r.next.Add(1)%uint64(len(r.servers))]
+
+		msg, err = r.Exchange(ctx, server, question, false)
+		if err != nil {
+			continue
+		}
+
+		if msg.RCode == dnsmessage.RCodeServerFailure || msg.RCode == dnsmessage.RCodeRefused {
+			err = fmt.Errorf("%w: %s for %s from %s", ErrServerFailure, msg.RCode, name, server)
+
+			continue
+		}
+
+		return
+	}
+
+	return
+}
+
+// LookupHost returns the IPv4 and IPv6 addresses name resolves to, following
+// the CNAME chain returned by the resolver. A name that does not resolve has
+// no addresses and no error.
+func (r *Resolver) LookupHost(ctx context.Context, name string) (addresses []string, err error) {
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		var msg *dnsmessage.Message
+
+		msg, err = r.Query(ctx, name, qtype)
+		if err != nil {
+			return
+		}
+
+		for _, answer := range msg.Answers {
+			switch body := answer.Body.(type) {
+			case *dnsmessage.AResource:
+				addresses = append(addresses, net.IP(body.A[:]).String())
+			case *dnsmessage.AAAAResource:
+				addresses = append(addresses, net.IP(body.AAAA[:]).String())
+			}
+		}
+	}
+
+	return
+}
+
+// maxCNAMEChain caps the length of the CNAME chains followed.
+const maxCNAMEChain = 10
+
+// LookupCNAME returns the chain of canonical names name points to, in order,
+// as found in the answer to an A query for it, with the response code of that
+// answer. A non-empty chain answered with NXDOMAIN means its last name does
+// not exist: the CNAME dangles.
+func (r *Resolver) LookupCNAME(ctx context.Context, name string) (chain []string, rcode dnsmessage.RCode, err error) {
+	var msg *dnsmessage.Message
+
+	msg, err = r.Query(ctx, name, dnsmessage.TypeA)
+	if err != nil {
+		return
+	}
+
+	rcode = msg.RCode
+
+	targets := map[string]string{}
+
+	for _, answer := range msg.Answers {
+		if body, ok := answer.Body.(*dnsmessage.CNAMEResource); ok {
+			targets[strings.ToLower(answer.Header.Name.String())] = strings.ToLower(body.CNAME.String())
+		}
+	}
+
+	current := strings.ToLower(fqdn(name))
+
+	for range maxCNAMEChain {
+		target, ok := targets[current]
+		if !ok {
+			break
+		}
+
+		chain = append(chain, strings.TrimSuffix(target, "."))
+
+		current = target
+	}
+
+	return
+}
+
+// Exchange sends a single query for question to server, over UDP, then over
+// TCP if the answer is truncated. If dnssec is true, DNSSEC records are
+// requested too, by setting the EDNS(0) DO bit.
+func (r *Resolver) Exchange(ctx context.Context, server string, question dnsmessage.Question, dnssec bool) (msg *dnsmessage.Message, err error) {
+	msg, err = r.exchange(ctx, "udp", server, question, dnssec)
+	if err == nil && msg.Truncated {
+		msg, err = r.exchange(ctx, "tcp", server, question, dnssec)
+	}
+
+	return
+}
+
+func (r *Resolver) exchange(ctx context.Context, network, server string, question dnsmessage.Question, dnssec bool) (msg *dnsmessage.Message, err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+
+	defer cancel()
+
+	var query []byte
+
+	var id uint16
+
+	query, id, err = NewQuery(question, dnssec)
+	if err != nil {
+		return
+	}
+
+	var conn net.Conn
+
+	conn, err = Dial(ctx, network, server)
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	if err = WriteMessage(conn, query); err != nil {
+		return
+	}
+
+	// UDP answers to other, timed out, queries may still arrive: skip them.
+	for {
+		msg, err = ReadMessage(conn)
+		if err != nil {
+			return
+		}
+
+		if msg.ID == id {
+			return
+		}
+
+		if network == "tcp" {
+			err = ErrIDMismatch
+
+			return
+		}
+	}
+}
+
+// NewQuery packs a recursive query for question, advertising EDNS(0). If
+// dnssec is true, the DO bit is set to request DNSSEC records.
+func NewQuery(question dnsmessage.Question, dnssec bool) (query []byte, id uint16, err error) {
+	var raw [2]byte
+
+	if _, err = rand.Read(raw[:]); err != nil {
+		return
+	}
+
+	id = binary.BigEndian.Uint16(raw[:])
+
+	builder := dnsmessage.NewBuilder(make([]byte, 2, 514), dnsmessage.Header{
+		ID:               id,
+		RecursionDesired: true,
+	})
+
+	builder.EnableCompression()
+
+	if err = builder.StartQuestions(); err != nil {
+		return
+	}
+
+	if err = builder.Question(question); err != nil {
+		return
+	}
+
+	if err = builder.StartAdditionals(); err != nil {
+		return
+	}
+
+	var opt dnsmessage.ResourceHeader
+
+	if err = opt.SetEDNS0(udpPayloadSize, dnsmessage.RCodeSuccess, false); err != nil {
+		return
+	}
+
+	if dnssec {
+		opt.TTL |= ednsDNSSECOK
+	}
+
+	if err = builder.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+		return
+	}
+
+	query, err = builder.Finish()
+	if err != nil {
+		return
+	}
+
+	// The first two bytes are room for the TCP length prefix, see WriteMessage.
+	binary.BigEndian.PutUint16(query, uint16(len(query)-2))
+
+	return
+}
+
+// Dial connects to server over network ("udp" or "tcp"). The connection
+// deadline is set from ctx, if it has one.
+func Dial(ctx context.Context, network, server string) (conn net.Conn, err error) {
+	if _, _, splitErr := net.SplitHostPort(server); splitErr != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	dialer := &net.Dialer{}
+
+	conn, err = dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+
+			return
+		}
+	}
+
+	// Unblock reads and writes once ctx is canceled.
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+
+	conn = &contextConn{Conn: conn, stop: stop}
+
+	return
+}
+
+type contextConn struct {
+	net.Conn
+
+	stop func() bool
+}
+
+func (conn *contextConn) Close() error {
+	conn.stop()
+
+	return conn.Conn.Close()
+}
+
+// WriteMessage writes a message packed by NewQuery, whose first two bytes are
+// reserved for the TCP length prefix, to conn.
+func WriteMessage(conn net.Conn, message []byte) (err error) {
+	if isPacketConn(conn) {
+		message = message[2:]
+	}
+
+	_, err = conn.Write(message)
+
+	return
+}
+
+// ReadMessage reads and parses a single message from conn.
+func ReadMessage(conn net.Conn) (msg *dnsmessage.Message, err error) {
+	var raw []byte
+
+	if isPacketConn(conn) {
+		buffer := make([]byte, 65535)
+
+		var n int
+
+		n, err = conn.Read(buffer)
+		if err != nil {
+			return
+		}
+
+		raw = buffer[:n]
+	} else {
+		var length [2]byte
+
+		if _, err = io.ReadFull(conn, length[:]); err != nil {
+			return
+		}
+
+		raw = make([]byte, binary.BigEndian.Uint16(length[:]))
+
+		if _, err = io.ReadFull(conn, raw); err != nil {
+			return
+		}
+	}
+
+	msg = &dnsmessage.Message{}
+
+	if err = msg.Unpack(raw); err != nil {
+		msg = nil
+	}
+
+	return
+}
+
+func isPacketConn(conn net.Conn) bool {
+	if c, ok := conn.(*contextConn); ok {
+		conn = c.Conn
+	}
+
+	_, ok := conn.(net.PacketConn)
+
+	return ok
+}
+
+// fqdn returns name as a fully qualified domain name, with a trailing dot.
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
+
+// New creates a Resolver with the given configuration, using the default
+// servers and timeout for options left unset. Retries are taken as they are,
+// negative numbers meaning none.
+func New(cfg *Configuration) (r *Resolver) {
+	r = &Resolver{
+		servers: cfg.Servers,
+		timeout: cfg.Timeout,
+		retries: cfg.Retries,
+	}
+
+	if len(r.servers) == 0 {
+		r.servers = DefaultServers
+	}
+
+	if r.timeout <= 0 {
+		r.timeout = defaultTimeout
+	}
+
+	if r.retries < 0 {
+		r.retries = 0
+	}
+
+	return
+}
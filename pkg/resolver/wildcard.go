@@ -0,0 +1,112 @@
+package resolver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+// Wildcards detects names that only resolve because of a wildcard record,
+// e.g. `*.example.com`. The wildcard addresses of a zone are learnt by
+// resolving random, non-existent, labels under it; a name whose addresses are
+// all wildcard addresses of one of its parents is a wildcard match.
+type Wildcards struct {
+	resolver *Resolver
+
+	// zones maps a parent name to its *zone, probed once on first use.
+	zones sync.Map
+}
+
+type zone struct {
+	once      sync.Once
+	addresses map[string]struct{}
+}
+
+// wildcardProbes is the number of random labels resolved per parent. Wildcards
+// backed by DNS load balancing answer with varying addresses, more probes
+// learn more of them.
+const wildcardProbes = 3
+
+// IsWildcard reports whether name, which resolved to addresses, is a wildcard
+// match. Parents of name are checked up to, and including, domain.
+func (w *Wildcards) IsWildcard(ctx context.Context, domain, name string, addresses []string) bool {
+	if len(addresses) == 0 {
+		return false
+	}
+
+	parent := name
+
+	for parent != domain {
+		index := strings.IndexByte(parent, '.')
+		if index == -1 {
+			break
+		}
+
+		parent = parent[index+1:]
+
+		wildcard := w.addresses(ctx, parent)
+
+		if len(wildcard) == 0 {
+			continue
+		}
+
+		matched := true
+
+		for _, address := range addresses {
+			if _, ok := wildcard[address]; !ok {
+				matched = false
+
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *Wildcards) addresses(ctx context.Context, parent string) map[string]struct{} {
+	value, _ := w.zones.LoadOrStore(parent, &zone{})
+
+	z, _ := value.(*zone)
+
+	z.once.Do(func() {
+		z.addresses = map[string]struct{}{}
+
+		for range wildcardProbes {
+			addresses, err := w.resolver.LookupHost(ctx, RandomLabel()+"."+parent)
+			if err != nil {
+				continue
+			}
+
+			for _, address := range addresses {
+				z.addresses[address] = struct{}{}
+			}
+		}
+	})
+
+	return z.addresses
+}
+
+// RandomLabel returns a random label, unlikely to exist in any zone.
+func RandomLabel() string {
+	raw := make([]byte, 8)
+
+	_, _ = rand.Read(raw)
+
+	return hex.EncodeToString(raw)
+}
+
+// NewWildcards creates a wildcard detector resolving its probes with r.
+func NewWildcards(r *Resolver) (w *Wildcards) {
+	w = &Wildcards{
+		resolver: r,
+	}
+
+	return
+}
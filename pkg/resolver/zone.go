@@ -0,0 +1,254 @@
+package resolver
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // NSEC3 hashes are SHA-1 by definition (RFC 5155).
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Types of DNSSEC records that dnsmessage does not define.
+const (
+	TypeNSEC  dnsmessage.Type = 47
+	TypeNSEC3 dnsmessage.Type = 50
+)
+
+// NSEC3 is the content of an NSEC3 record (RFC 5155) that matters to
+// enumeration: the hashes of the owner and the next owner names, and the
+// parameters needed to hash candidate names the same way.
+type NSEC3 struct {
+	// Hash is the hash of the owner name, in lowercase base32hex.
+	Hash string
+	// Next is the hash of the next owner name, in lowercase base32hex.
+	Next       string
+	Algorithm  uint8
+	Iterations uint16
+	Salt       []byte
+}
+
+// nsec3HashSHA1 is the only NSEC3 hash algorithm defined.
+const nsec3HashSHA1 = 1
+
+var (
+	base32Hex = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+	ErrTransferFailed = errors.New("zone transfer failed")
+	ErrMalformedRData = errors.New("malformed record data")
+)
+
+// Transfer requests a full zone transfer (AXFR) of zone from server, over
+// TCP, and returns the records of the zone. Transfers are usually refused,
+// only misconfigured servers answer them.
+func (r *Resolver) Transfer(ctx context.Context, server, zone string) (records []dnsmessage.Resource, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*r.timeout)
+
+	defer cancel()
+
+	question := dnsmessage.Question{
+		Type:  dnsmessage.TypeAXFR,
+		Class: dnsmessage.ClassINET,
+	}
+
+	question.Name, err = dnsmessage.NewName(fqdn(zone))
+	if err != nil {
+		return
+	}
+
+	var query []byte
+
+	var id uint16
+
+	query, id, err = NewQuery(question, false)
+	if err != nil {
+		return
+	}
+
+	conn, err := Dial(ctx, "tcp", server)
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	if err = WriteMessage(conn, query); err != nil {
+		return
+	}
+
+	// The zone is sent as a stream of messages, starting and ending with its SOA record.
+	soas := 0
+
+	for soas < 2 {
+		var msg *dnsmessage.Message
+
+		msg, err = ReadMessage(conn)
+		if err != nil {
+			return
+		}
+
+		if msg.ID != id {
+			err = ErrIDMismatch
+
+			return
+		}
+
+		if msg.RCode != dnsmessage.RCodeSuccess || (len(records) == 0 && len(msg.Answers) == 0) {
+			err = fmt.Errorf("%w: %s from %s", ErrTransferFailed, msg.RCode, server)
+
+			return
+		}
+
+		for _, answer := range msg.Answers {
+			if answer.Header.Type == dnsmessage.TypeSOA {
+				soas++
+			}
+
+			if len(records) == 0 && answer.Header.Type != dnsmessage.TypeSOA {
+				err = fmt.Errorf("%w: first record is not SOA", ErrTransferFailed)
+
+				return
+			}
+
+			records = append(records, answer)
+		}
+	}
+
+	return
+}
+
+// ParseNSEC returns the next owner name of an NSEC record (RFC 4034).
+func ParseNSEC(data []byte) (next string, err error) {
+	next, _, err = readName(data)
+
+	return
+}
+
+// ParseNSEC3 parses an NSEC3 record (RFC 5155) owned by owner, e.g.
+// `<hash>.example.com.`.
+func ParseNSEC3(owner string, data []byte) (record NSEC3, err error) {
+	hash, _, _ := strings.Cut(owner, ".")
+
+	record.Hash = strings.ToLower(hash)
+
+	// hash algorithm (1), flags (1), iterations (2), salt length (1).
+	if len(data) < 5 {
+		err = ErrMalformedRData
+
+		return
+	}
+
+	record.Algorithm = data[0]
+	record.Iterations = binary.BigEndian.Uint16(data[2:4])
+
+	offset := 5 + int(data[4])
+
+	if len(data) < offset+1 {
+		err = ErrMalformedRData
+
+		return
+	}
+
+	record.Salt = data[5:offset]
+
+	length := int(data[offset])
+
+	offset++
+
+	if len(data) < offset+length {
+		err = ErrMalformedRData
+
+		return
+	}
+
+	record.Next = strings.ToLower(base32Hex.EncodeToString(data[offset : offset+length]))
+
+	return
+}
+
+// HashNSEC3 hashes name the way NSEC3 records of a zone with the given salt
+// and iterations do, and returns the hash in lowercase base32hex.
+func HashNSEC3(name string, salt []byte, iterations uint16) (hash string, err error) {
+	var wire []byte
+
+	wire, err = wireName(name)
+	if err != nil {
+		return
+	}
+
+	digest := sha1.Sum(append(wire, salt...)) //nolint:gosec // See import.
+
+	for range iterations {
+		digest = sha1.Sum(append(digest[:], salt...)) //nolint:gosec // See import.
+	}
+
+	hash = strings.ToLower(base32Hex.EncodeToString(digest[:]))
+
+	return
+}
+
+// Supported reports whether the hash algorithm of record is supported by HashNSEC3.
+func (record NSEC3) Supported() bool {
+	return record.Algorithm == nsec3HashSHA1
+}
+
+// wireName returns the lowercase, uncompressed, wire format of name.
+func wireName(name string) (wire []byte, err error) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if label == "" || len(label) > 63 {
+				err = fmt.Errorf("%w: invalid name %q", ErrMalformedRData, name)
+
+				return
+			}
+
+			wire = append(wire, byte(len(label)))
+			wire = append(wire, label...)
+		}
+	}
+
+	wire = append(wire, 0)
+
+	return
+}
+
+// readName reads an uncompressed, wire format, name from data, as found in
+// record data that dnsmessage does not parse, and returns it with a trailing dot.
+func readName(data []byte) (name string, n int, err error) {
+	var labels []string
+
+	for {
+		if n >= len(data) {
+			err = ErrMalformedRData
+
+			return
+		}
+
+		length := int(data[n])
+
+		n++
+
+		if length == 0 {
+			break
+		}
+
+		if length > 63 || n+length > len(data) {
+			err = ErrMalformedRData
+
+			return
+		}
+
+		labels = append(labels, string(data[n:n+length]))
+
+		n += length
+	}
+
+	name = strings.Join(labels, ".") + "."
+
+	return
+}
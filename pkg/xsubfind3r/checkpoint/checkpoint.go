@@ -0,0 +1,305 @@
+package checkpoint
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Checkpoint records the progress of a run so that it can be resumed after a
+// crash or an interruption. Progress is appended to a JSON lines file as it
+// happens, one event per line, which keeps the file consistent even if the
+// process dies mid-write: at worst the last, partial, line is ignored.
+//
+// Four kinds of progress are tracked:
+//   - (domain, source) pairs that ran to completion, so they can be skipped.
+//   - subdomains already written out, so they are not written twice.
+//   - pagination cursors, so paginated sources can continue where they stopped.
+//   - sibling root domains found, so they are still enumerated once the
+//     sources that found them are skipped.
+type Checkpoint struct {
+	mutex sync.Mutex
+
+	path string
+	file *os.File
+
+	done    map[key]struct{}
+	written map[key]struct{}
+	cursors map[key]string
+	roots   map[string][]string
+	rooted  map[key]struct{}
+}
+
+type key struct {
+	domain string
+	value  string
+}
+
+type event struct {
+	Event  string `json:"event"`
+	Domain string `json:"domain"`
+	Source string `json:"source,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+// Types of events recorded in the checkpoint file.
+const (
+	eventSourceDone = "source_done"
+	eventWritten    = "written"
+	eventCursor     = "cursor"
+	eventRoot       = "root"
+)
+
+// IsSourceDone reports whether source already ran to completion for domain.
+func (c *Checkpoint) IsSourceDone(domain, source string) (done bool) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	_, done = c.done[key{domain, source}]
+
+	return
+}
+
+// MarkSourceDone records that source ran to completion for domain.
+func (c *Checkpoint) MarkSourceDone(domain, source string) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	c.done[key{domain, source}] = struct{}{}
+
+	delete(c.cursors, key{domain, source})
+
+	c.append(event{Event: eventSourceDone, Domain: domain, Source: source})
+}
+
+// IsWritten reports whether subdomain was already written out for domain.
+func (c *Checkpoint) IsWritten(domain, subdomain string) (written bool) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	_, written = c.written[key{domain, subdomain}]
+
+	return
+}
+
+// Written returns the subdomains already written out for domain, e.g. by the
+// sources done before a resume.
+func (c *Checkpoint) Written(domain string) (subdomains []string) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	for k := range c.written {
+		if k.domain == domain {
+			subdomains = append(subdomains, k.value)
+		}
+	}
+
+	sort.Strings(subdomains)
+
+	return
+}
+
+// MarkWritten records that subdomain was written out for domain.
+func (c *Checkpoint) MarkWritten(domain, subdomain string) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	c.written[key{domain, subdomain}] = struct{}{}
+
+	c.append(event{Event: eventWritten, Domain: domain, Value: subdomain})
+}
+
+// Cursor returns the last pagination cursor recorded by source for domain,
+// or an empty string if there is none.
+func (c *Checkpoint) Cursor(domain, source string) (cursor string) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	cursor = c.cursors[key{domain, source}]
+
+	return
+}
+
+// SetCursor records the pagination cursor from which source should continue for domain.
+func (c *Checkpoint) SetCursor(domain, source, cursor string) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	c.cursors[key{domain, source}] = cursor
+
+	c.append(event{Event: eventCursor, Domain: domain, Source: source, Value: cursor})
+}
+
+// Roots returns the sibling root domains found for domain, in the order found.
+func (c *Checkpoint) Roots(domain string) (roots []string) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	roots = append(roots, c.roots[domain]...)
+
+	return
+}
+
+// MarkRoot records that root was found as a sibling root domain of domain.
+func (c *Checkpoint) MarkRoot(domain, root string) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	if !c.addRoot(domain, root) {
+		return
+	}
+
+	c.append(event{Event: eventRoot, Domain: domain, Value: root})
+}
+
+func (c *Checkpoint) addRoot(domain, root string) (added bool) {
+	if _, ok := c.rooted[key{domain, root}]; ok {
+		return
+	}
+
+	c.rooted[key{domain, root}] = struct{}{}
+	c.roots[domain] = append(c.roots[domain], root)
+
+	added = true
+
+	return
+}
+
+// Close closes the checkpoint file, keeping it on disk for a later resume.
+func (c *Checkpoint) Close() (err error) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	err = c.file.Close()
+
+	return
+}
+
+// Remove closes and deletes the checkpoint file, used once a run completes.
+func (c *Checkpoint) Remove() (err error) {
+	if err = c.Close(); err != nil {
+		return
+	}
+
+	err = os.Remove(c.path)
+
+	return
+}
+
+func (c *Checkpoint) append(e event) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	line = append(line, '\n')
+
+	// Errors are deliberately ignored: losing a checkpoint event only means
+	// some work is redone on resume, it must never interrupt the run itself.
+	_, _ = c.file.Write(line)
+}
+
+func (c *Checkpoint) load() (err error) {
+	var file *os.File
+
+	file, err = os.Open(c.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+
+		return
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		var e event
+
+		// A line that fails to decode is the partial tail of an interrupted write.
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+
+		switch e.Event {
+		case eventSourceDone:
+			c.done[key{e.Domain, e.Source}] = struct{}{}
+
+			delete(c.cursors, key{e.Domain, e.Source})
+		case eventWritten:
+			c.written[key{e.Domain, e.Value}] = struct{}{}
+		case eventCursor:
+			c.cursors[key{e.Domain, e.Source}] = e.Value
+		case eventRoot:
+			c.addRoot(e.Domain, e.Value)
+		}
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+// Open opens the checkpoint file at path. If resume is true, progress already
+// recorded in the file is loaded and new progress is appended to it; otherwise
+// the file is truncated and the run starts from scratch.
+func Open(path string, resume bool) (c *Checkpoint, err error) {
+	c = &Checkpoint{
+		path:    path,
+		done:    map[key]struct{}{},
+		written: map[key]struct{}{},
+		cursors: map[key]string{},
+		roots:   map[string][]string{},
+		rooted:  map[key]struct{}{},
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+
+	if resume {
+		if err = c.load(); err != nil {
+			return
+		}
+
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return
+	}
+
+	c.file, err = os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return
+	}
+
+	// Terminate a partial last line left by an interrupted write, so that
+	// the first appended event starts on a line of its own.
+	if resume {
+		var info os.FileInfo
+
+		info, err = c.file.Stat()
+		if err != nil || info.Size() == 0 {
+			return
+		}
+
+		_, err = c.file.WriteString("\n")
+	}
+
+	return
+}
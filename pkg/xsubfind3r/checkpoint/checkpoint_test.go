@@ -0,0 +1,48 @@
+package checkpoint_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/checkpoint"
+)
+
+func TestCheckpointKeepsRootsAcrossResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+
+	progress, err := checkpoint.Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progress.MarkRoot("example.com", "example.net")
+	progress.MarkRoot("example.com", "example.org")
+	progress.MarkRoot("example.com", "example.net")
+	progress.MarkSourceDone("example.com", "whois")
+
+	if err = progress.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err = checkpoint.Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		progress.Close()
+	})
+
+	if !progress.IsSourceDone("example.com", "whois") {
+		t.Error("whois not done after resume")
+	}
+
+	if got, want := progress.Roots("example.com"), []string{"example.net", "example.org"}; !slices.Equal(got, want) {
+		t.Errorf("Roots() = %v, want %v", got, want)
+	}
+
+	if got := progress.Roots("example.net"); len(got) != 0 {
+		t.Errorf("Roots() of a domain without roots = %v, want none", got)
+	}
+}
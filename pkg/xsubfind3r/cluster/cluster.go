@@ -0,0 +1,201 @@
+package cluster
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// DefaultMaxDistance is the number of differing bits, out of 64, under which
+// the SimHashes of two bodies are deemed similar when none is configured.
+const DefaultMaxDistance = 6
+
+// shingleSize is the number of consecutive tokens hashed together.
+const shingleSize = 3
+
+var (
+	// tokenRegex matches the tokens of bodies, words and tag names alike, so
+	// that both the text and the structure of pages are compared.
+	tokenRegex = regexp.MustCompile(`[\pL\pN]+`)
+
+	// volatileHeaders change between responses of the same application and are
+	// left out of header fingerprints.
+	volatileHeaders = map[string]struct{}{
+		"age":                   {},
+		"content-length":        {},
+		"date":                  {},
+		"etag":                  {},
+		"expires":               {},
+		"last-modified":         {},
+		"location":              {},
+		"set-cookie":            {},
+		"x-request-id":          {},
+		"x-amz-request-id":      {},
+		"x-amz-id-2":            {},
+		"x-cache":               {},
+		"x-served-by":           {},
+		"x-timer":               {},
+		"cf-ray":                {},
+		"report-to":             {},
+		"nel":                   {},
+		"x-correlation-id":      {},
+		"x-runtime":             {},
+		"x-azure-ref":           {},
+		"x-ms-request-id":       {},
+		"x-cloud-trace-context": {},
+	}
+)
+
+// SimHash returns the 64-bit SimHash of body, computed over the shingles of
+// its lowercased tokens: similar bodies have hashes differing in few bits.
+func SimHash(body []byte) (hash uint64) {
+	tokens := tokenRegex.FindAllString(strings.ToLower(string(body)), -1)
+
+	if len(tokens) == 0 {
+		return
+	}
+
+	var weights [64]int
+
+	for start := 0; start+shingleSize <= len(tokens) || start == 0; start++ {
+		end := min(start+shingleSize, len(tokens))
+
+		h := fnv.New64a()
+
+		h.Write([]byte(strings.Join(tokens[start:end], " ")))
+
+		sum := h.Sum64()
+
+		for bit := range 64 {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	for bit := range 64 {
+		if weights[bit] > 0 {
+			hash |= 1 << bit
+		}
+	}
+
+	return
+}
+
+// Distance returns the number of bits a and b differ in.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// HeaderFingerprint returns a fingerprint of header: a hash of the names of
+// its headers, volatile ones left out, and of its Server header.
+func HeaderFingerprint(header http.Header) string {
+	names := make([]string, 0, len(header))
+
+	for name := range header {
+		name = strings.ToLower(name)
+
+		if _, ok := volatileHeaders[name]; ok {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	sum := sha256.Sum256([]byte(strings.Join(names, ",") + "\n" + header.Get("Server")))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// Assign groups the probe results of results into clusters of similar
+// responses, setting their Cluster: responses with the same status code and
+// header fingerprint, and bodies at most maxDistance bits apart, are in the
+// same cluster. The representative of a cluster is its member with the
+// shortest hostname. Clusters are numbered from 1, largest first. A negative
+// maxDistance is DefaultMaxDistance, 0 only groups bodies hashing the same.
+func Assign(results []sources.Result, maxDistance int) {
+	if maxDistance < 0 {
+		maxDistance = DefaultMaxDistance
+	}
+
+	// Sorting first makes the clusters, and their numbers, deterministic.
+	indexes := make([]int, 0, len(results))
+
+	for index := range results {
+		if results[index].Type == sources.ResultProbe && results[index].Response != nil {
+			indexes = append(indexes, index)
+		}
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a, b := results[indexes[i]], results[indexes[j]]
+
+		if len(a.Subdomain) != len(b.Subdomain) {
+			return len(a.Subdomain) < len(b.Subdomain)
+		}
+
+		return a.Value < b.Value
+	})
+
+	type group struct {
+		leader  *sources.Response
+		members []int
+	}
+
+	var groups []*group
+
+	for _, index := range indexes {
+		response := results[index].Response
+
+		var joined *group
+
+		for _, g := range groups {
+			if g.leader.StatusCode == response.StatusCode &&
+				g.leader.HeaderFingerprint == response.HeaderFingerprint &&
+				Distance(g.leader.SimHash, response.SimHash) <= maxDistance {
+				joined = g
+
+				break
+			}
+		}
+
+		if joined == nil {
+			joined = &group{
+				leader: response,
+			}
+
+			groups = append(groups, joined)
+		}
+
+		joined.members = append(joined.members, index)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].members) > len(groups[j].members)
+	})
+
+	for number, g := range groups {
+		representative := results[g.members[0]]
+
+		cluster := &sources.Cluster{
+			ID:             number + 1,
+			Size:           len(g.members),
+			Representative: representative.Value,
+		}
+
+		for _, index := range g.members {
+			results[index].Cluster = cluster
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package cluster_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/cluster"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// parked returns a parking page naming domain and stamped with id, as such
+// pages are, e.g. with a request or session identifier.
+func parked(domain, id string) []byte {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "<html><head><title>%s is parked</title></head><body>", domain)
+
+	for index := range 20 {
+		fmt.Fprintf(&builder, "<div class=\"offer\"><p>This domain may be for sale, offer %d, contact the owner through our marketplace today.</p></div>", index)
+	}
+
+	fmt.Fprintf(&builder, "<footer>request %s</footer></body></html>", id)
+
+	return []byte(builder.String())
+}
+
+func login() []byte {
+	var builder strings.Builder
+
+	builder.WriteString("<html><head><title>Sign in</title></head><body><form>")
+
+	for index := range 20 {
+		fmt.Fprintf(&builder, "<label>Field %d</label><input name=\"field%d\" type=\"text\"> required by the single sign on portal of the corporate network.", index, index)
+	}
+
+	builder.WriteString("</form></body></html>")
+
+	return []byte(builder.String())
+}
+
+func TestSimHashNearIdenticalBodies(t *testing.T) {
+	a := cluster.SimHash(parked("a.example.com", "7f3a9c"))
+	b := cluster.SimHash(parked("b.example.com", "e21d04"))
+	c := cluster.SimHash(login())
+
+	if distance := cluster.Distance(a, b); distance > cluster.DefaultMaxDistance {
+		t.Errorf("distance of near-identical bodies = %d, want at most %d", distance, cluster.DefaultMaxDistance)
+	}
+
+	if distance := cluster.Distance(a, c); distance <= cluster.DefaultMaxDistance {
+		t.Errorf("distance of different bodies = %d, want more than %d", distance, cluster.DefaultMaxDistance)
+	}
+
+	if cluster.SimHash(nil) != 0 {
+		t.Error("SimHash of an empty body is not 0")
+	}
+}
+
+func TestHeaderFingerprintIgnoresVolatileHeaders(t *testing.T) {
+	a := http.Header{
+		"Server":       {"nginx"},
+		"Content-Type": {"text/html"},
+		"Date":         {"Mon, 19 Oct 2026 10:00:00 GMT"},
+		"Set-Cookie":   {"session=1"},
+	}
+
+	b := http.Header{
+		"Server":       {"nginx"},
+		"Content-Type": {"text/html; charset=utf-8"},
+		"Date":         {"Mon, 19 Oct 2026 10:00:05 GMT"},
+		"X-Request-Id": {"2"},
+	}
+
+	if cluster.HeaderFingerprint(a) != cluster.HeaderFingerprint(b) {
+		t.Error("fingerprints differ by volatile headers")
+	}
+
+	b.Set("Server", "Apache")
+
+	if cluster.HeaderFingerprint(a) == cluster.HeaderFingerprint(b) {
+		t.Error("fingerprints of different servers are equal")
+	}
+}
+
+func TestAssign(t *testing.T) {
+	probe := func(subdomain string, status int, body []byte) sources.Result {
+		return sources.Result{
+			Type:      sources.ResultProbe,
+			Subdomain: subdomain,
+			Value:     "https://" + subdomain + "/",
+			Response: &sources.Response{
+				StatusCode:        status,
+				SimHash:           cluster.SimHash(body),
+				HeaderFingerprint: "nginx",
+			},
+		}
+	}
+
+	results := []sources.Result{
+		probe("shop.example.com", 200, parked("shop.example.com", "1")),
+		probe("sso.example.com", 200, login()),
+		{Type: sources.ResultSubdomain, Value: "www.example.com"},
+		probe("a.example.com", 200, parked("a.example.com", "2")),
+		probe("blog.example.com", 200, parked("blog.example.com", "3")),
+		probe("gone.example.com", 404, parked("gone.example.com", "4")),
+	}
+
+	cluster.Assign(results, -1)
+
+	parkedCluster := results[0].Cluster
+
+	if parkedCluster == nil || parkedCluster.ID != 1 || parkedCluster.Size != 3 {
+		t.Fatalf("cluster of the parked pages = %+v, want the first, of 3", parkedCluster)
+	}
+
+	if parkedCluster.Representative != "https://a.example.com/" {
+		t.Errorf("representative = %s, want that of the shortest hostname", parkedCluster.Representative)
+	}
+
+	for _, index := range []int{3, 4} {
+		if results[index].Cluster != parkedCluster {
+			t.Errorf("%s not clustered with the parked pages", results[index].Subdomain)
+		}
+	}
+
+	for _, index := range []int{1, 5} {
+		if results[index].Cluster == parkedCluster || results[index].Cluster.Size != 1 {
+			t.Errorf("%s cluster = %+v, want its own", results[index].Subdomain, results[index].Cluster)
+		}
+	}
+
+	if results[2].Cluster != nil {
+		t.Error("result other than a probe clustered")
+	}
+}
+
+func TestAssignMaxDistance(t *testing.T) {
+	probe := func(subdomain string, simHash uint64) sources.Result {
+		return sources.Result{
+			Type:      sources.ResultProbe,
+			Subdomain: subdomain,
+			Value:     "https://" + subdomain + "/",
+			Response:  &sources.Response{StatusCode: 200, SimHash: simHash},
+		}
+	}
+
+	results := []sources.Result{
+		probe("a.example.com", 0b1010),
+		probe("b.example.com", 0b1011),
+		probe("c.example.com", 0b1010),
+	}
+
+	// A distance of 0 only clusters bodies hashing the same.
+	cluster.Assign(results, 0)
+
+	if results[0].Cluster != results[2].Cluster || results[0].Cluster == results[1].Cluster {
+		t.Errorf("clusters with a distance of 0 = %+v, %+v, %+v, want a and c apart from b", results[0].Cluster, results[1].Cluster, results[2].Cluster)
+	}
+
+	// A negative distance is the default one.
+	cluster.Assign(results, -1)
+
+	if results[0].Cluster != results[1].Cluster || results[0].Cluster != results[2].Cluster {
+		t.Errorf("clusters with the default distance = %+v, %+v, %+v, want one", results[0].Cluster, results[1].Cluster, results[2].Cluster)
+	}
+}
@@ -0,0 +1,202 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// Enricher attaches to subdomains the IP addresses sources found them with,
+// or they resolve to, and the networks those belong to, looked up in offline
+// ASN databases. It is safe for concurrent use.
+type Enricher struct {
+	resolver *resolver.Resolver
+	resolve  bool
+
+	databases []database
+
+	excludeASN map[uint]struct{}
+
+	concurrency int
+}
+
+// Configuration holds the enrichment settings loaded from `config.yaml`.
+type Configuration struct {
+	// Databases are the ASN database files looked up, MaxMind (`.mmdb`, e.g.
+	// GeoLite2-ASN and GeoLite2-Country) or iptoasn (`.tsv`, gzipped or not).
+	// Each field of a network is taken from the first database holding it.
+	Databases []string `yaml:"databases" mapstructure:"databases"`
+	// Resolve, if true, resolves subdomains to add the addresses they resolve to.
+	Resolve bool `yaml:"resolve" mapstructure:"resolve"`
+	// ExcludeASN are the autonomous systems, e.g. "AS13335" or "13335", whose
+	// subdomains are dropped, when all their addresses belong to them.
+	ExcludeASN []string `yaml:"exclude_asn" mapstructure:"exclude_asn"`
+	// Concurrency is the number of subdomains enriched at once.
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+}
+
+// database is an ASN database.
+type database interface {
+	// lookup sets the fields of address left empty that the database holds
+	// for ip.
+	lookup(ip netip.Addr, address *sources.Address)
+	close() error
+}
+
+// NAME is the source of the results of enrichment.
+const NAME = "enrichment"
+
+const defaultConcurrency = 50
+
+var (
+	ErrInvalidASN = errors.New("invalid ASN")
+	ErrNoDatabase = errors.New("excluding ASNs requires an ASN database")
+)
+
+// Enabled reports whether cfg turns enrichment on.
+func (cfg *Configuration) Enabled() bool {
+	return len(cfg.Databases) > 0 || cfg.Resolve || len(cfg.ExcludeASN) > 0
+}
+
+// Enrich returns the addresses of subdomain: ips, and those it resolves to if
+// resolution is on, with the networks they belong to. On resolution failure,
+// the addresses known are returned with the error.
+func (e *Enricher) Enrich(ctx context.Context, subdomain string, ips []string) (addresses []sources.Address, err error) {
+	seen := map[string]struct{}{}
+
+	add := func(ip string) {
+		parsed, err := netip.ParseAddr(ip)
+		if err != nil {
+			return
+		}
+
+		parsed = parsed.Unmap()
+
+		if _, ok := seen[parsed.String()]; ok {
+			return
+		}
+
+		seen[parsed.String()] = struct{}{}
+
+		address := sources.Address{
+			IP: parsed.String(),
+		}
+
+		for _, db := range e.databases {
+			db.lookup(parsed, &address)
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	for _, ip := range ips {
+		add(ip)
+	}
+
+	if !e.resolve {
+		return
+	}
+
+	var resolved []string
+
+	resolved, err = e.resolver.LookupHost(ctx, subdomain)
+
+	for _, ip := range resolved {
+		add(ip)
+	}
+
+	return
+}
+
+// Excluded reports whether all of addresses, if any, belong to excluded
+// autonomous systems.
+func (e *Enricher) Excluded(addresses []sources.Address) bool {
+	if len(e.excludeASN) == 0 || len(addresses) == 0 {
+		return false
+	}
+
+	for _, address := range addresses {
+		if _, ok := e.excludeASN[address.ASN]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Concurrency returns the number of subdomains to enrich at once.
+func (e *Enricher) Concurrency() int {
+	return e.concurrency
+}
+
+// Close closes the databases.
+func (e *Enricher) Close() (err error) {
+	for _, db := range e.databases {
+		err = errors.Join(err, db.close())
+	}
+
+	return
+}
+
+// New creates an Enricher from cfg, opening its databases. Resolution goes
+// through r.
+func New(cfg *Configuration, r *resolver.Resolver) (e *Enricher, err error) {
+	e = &Enricher{
+		resolver:    r,
+		resolve:     cfg.Resolve,
+		excludeASN:  map[uint]struct{}{},
+		concurrency: cfg.Concurrency,
+	}
+
+	if e.concurrency < 1 {
+		e.concurrency = defaultConcurrency
+	}
+
+	for _, value := range cfg.ExcludeASN {
+		var number uint64
+
+		number, err = strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "AS"), 10, 32)
+		if err != nil || number == 0 {
+			err = fmt.Errorf("%w: %q", ErrInvalidASN, value)
+
+			return
+		}
+
+		e.excludeASN[uint(number)] = struct{}{}
+	}
+
+	if len(e.excludeASN) > 0 && len(cfg.Databases) == 0 {
+		err = ErrNoDatabase
+
+		return
+	}
+
+	for _, path := range cfg.Databases {
+		var db database
+
+		if strings.EqualFold(filepath.Ext(path), ".mmdb") {
+			db, err = openMMDB(path)
+		} else {
+			db, err = openIPToASN(path)
+		}
+
+		if err != nil {
+			err = fmt.Errorf("%s: %w", path, err)
+
+			e.Close()
+
+			return
+		}
+
+		e.databases = append(e.databases, db)
+	}
+
+	return
+}
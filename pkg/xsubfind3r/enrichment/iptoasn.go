@@ -0,0 +1,163 @@
+package enrichment
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// ipToASN is an iptoasn.com database, e.g. `ip2asn-combined.tsv.gz`: lines of
+// tab separated range start, range end, AS number, country code and AS
+// description.
+type ipToASN struct {
+	ranges []ipRange
+}
+
+type ipRange struct {
+	start, end   netip.Addr
+	asn          uint
+	country      string
+	organization string
+}
+
+func openIPToASN(path string) (db *ipToASN, err error) {
+	var file *os.File
+
+	file, err = os.Open(path)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	var reader io.Reader = file
+
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		var gz *gzip.Reader
+
+		gz, err = gzip.NewReader(file)
+		if err != nil {
+			return
+		}
+
+		defer gz.Close()
+
+		reader = gz
+	}
+
+	db = &ipToASN{}
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+
+		if len(fields) < 5 {
+			continue
+		}
+
+		// AS 0 marks ranges not routed.
+		asn, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil || asn == 0 {
+			continue
+		}
+
+		start, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			continue
+		}
+
+		end, err := netip.ParseAddr(fields[1])
+		if err != nil || end.Less(start) {
+			continue
+		}
+
+		country := fields[3]
+
+		if country == "None" {
+			country = ""
+		}
+
+		db.ranges = append(db.ranges, ipRange{
+			start:        start.Unmap(),
+			end:          end.Unmap(),
+			asn:          uint(asn),
+			country:      country,
+			organization: fields[4],
+		})
+	}
+
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+
+	return
+}
+
+func (db *ipToASN) lookup(ip netip.Addr, address *sources.Address) {
+	// the last range starting at or before ip is the only one that may hold it.
+	index := sort.Search(len(db.ranges), func(i int) bool {
+		return ip.Less(db.ranges[i].start)
+	}) - 1
+
+	if index < 0 || db.ranges[index].end.Less(ip) {
+		return
+	}
+
+	r := db.ranges[index]
+
+	if address.ASN == 0 {
+		address.ASN = r.asn
+		address.Organization = r.organization
+	}
+
+	if address.Country == "" {
+		address.Country = r.country
+	}
+
+	if address.CIDR == "" {
+		address.CIDR = r.prefix(ip).String()
+	}
+}
+
+// prefix returns the largest network holding ip within the range, ranges
+// being arbitrary and not always a single network.
+func (r ipRange) prefix(ip netip.Addr) (prefix netip.Prefix) {
+	for bits := 0; bits <= ip.BitLen(); bits++ {
+		prefix = netip.PrefixFrom(ip, bits).Masked()
+
+		if r.start.Compare(prefix.Addr()) <= 0 && last(prefix).Compare(r.end) <= 0 {
+			return
+		}
+	}
+
+	return
+}
+
+// last returns the last address of prefix.
+func last(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 0x80 >> (bit % 8)
+	}
+
+	addr, _ := netip.AddrFromSlice(bytes)
+
+	return addr
+}
+
+func (db *ipToASN) close() error {
+	return nil
+}
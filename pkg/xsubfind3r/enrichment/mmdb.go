@@ -0,0 +1,67 @@
+package enrichment
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// mmdb is a MaxMind database, e.g. GeoLite2-ASN, GeoLite2-Country or any
+// database with the same fields, like those of IPinfo or DB-IP.
+type mmdb struct {
+	reader *maxminddb.Reader
+}
+
+// mmdbRecord holds the fields of the MaxMind ASN and country databases used.
+type mmdbRecord struct {
+	AutonomousSystemNumber       uint   `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+	Country                      struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+func openMMDB(path string) (db *mmdb, err error) {
+	var reader *maxminddb.Reader
+
+	reader, err = maxminddb.Open(path)
+	if err != nil {
+		return
+	}
+
+	db = &mmdb{
+		reader: reader,
+	}
+
+	return
+}
+
+// lookup prefers the network of the ASN database to that of the country one.
+func (db *mmdb) lookup(ip netip.Addr, address *sources.Address) {
+	var record mmdbRecord
+
+	network, ok, err := db.reader.LookupNetwork(net.IP(ip.AsSlice()), &record)
+	if err != nil || !ok {
+		return
+	}
+
+	if address.ASN == 0 && record.AutonomousSystemNumber != 0 {
+		address.ASN = record.AutonomousSystemNumber
+		address.Organization = record.AutonomousSystemOrganization
+		address.CIDR = network.String()
+	}
+
+	if address.Country == "" {
+		address.Country = record.Country.ISOCode
+	}
+
+	if address.CIDR == "" {
+		address.CIDR = network.String()
+	}
+}
+
+func (db *mmdb) close() error {
+	return db.reader.Close()
+}
@@ -0,0 +1,219 @@
+package filter
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Filter decides which subdomains are kept, based on include (match) and
+// exclude (filter) patterns. A subdomain is kept when it matches at least one
+// include pattern, if any are set, and none of the exclude patterns.
+//
+// Patterns are globs by default, where `*` matches any run of characters
+// (dots included) and `?` matches a single character, e.g. `*.api.*` or
+// `*.cdn.example.com`. Patterns prefixed with `regex:` are regular expressions,
+// e.g. `regex:^api[0-9]+\.`. All patterns are matched case-insensitively.
+type Filter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// Configuration holds the patterns and scope files a Filter is built from.
+type Configuration struct {
+	// Match are the include patterns.
+	Match []string
+	// Filter are the exclude patterns.
+	Filter []string
+	// ScopeFiles are files listing in-scope hosts, they add include patterns.
+	ScopeFiles []string
+	// OutOfScopeFiles are files listing out-of-scope hosts, they add exclude patterns.
+	OutOfScopeFiles []string
+}
+
+// RegexPrefix marks a pattern as a regular expression instead of a glob.
+const RegexPrefix = "regex:"
+
+// Allow reports whether subdomain passes the filter. A nil Filter allows everything.
+func (f *Filter) Allow(subdomain string) (allowed bool) {
+	if f == nil {
+		allowed = true
+
+		return
+	}
+
+	for _, pattern := range f.exclude {
+		if pattern.MatchString(subdomain) {
+			return
+		}
+	}
+
+	if len(f.include) == 0 {
+		allowed = true
+
+		return
+	}
+
+	for _, pattern := range f.include {
+		if pattern.MatchString(subdomain) {
+			allowed = true
+
+			return
+		}
+	}
+
+	return
+}
+
+// Compile compiles a glob, or a `regex:` prefixed regular expression, into a
+// case-insensitive regular expression. Globs are anchored at both ends.
+func Compile(pattern string) (regex *regexp.Regexp, err error) {
+	if expression, ok := strings.CutPrefix(pattern, RegexPrefix); ok {
+		regex, err = regexp.Compile("(?i)" + expression)
+
+		return
+	}
+
+	builder := &strings.Builder{}
+
+	builder.WriteString("(?i)^")
+
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	builder.WriteString("$")
+
+	regex, err = regexp.Compile(builder.String())
+
+	return
+}
+
+// ReadScopeFile reads a scope file, one pattern per line, as found in bug
+// bounty program scope lists. Empty lines and `#` comments are skipped, and
+// URL-like entries are reduced to their host, e.g. `https://*.example.com/`
+// becomes `*.example.com`. `regex:` patterns are kept as they are.
+func ReadScopeFile(path string) (patterns []string, err error) {
+	var file *os.File
+
+	file, err = os.Open(path)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, RegexPrefix) {
+			line = host(line)
+		}
+
+		if line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+func host(entry string) string {
+	if _, after, ok := strings.Cut(entry, "://"); ok {
+		entry = after
+	}
+
+	if index := strings.IndexAny(entry, "/?#"); index != -1 {
+		entry = entry[:index]
+	}
+
+	// Ports are cut off, IPv6 literals, bracketed or not, are kept whole.
+	if hostname, _, err := net.SplitHostPort(entry); err == nil {
+		entry = hostname
+	} else if strings.HasPrefix(entry, "[") && strings.HasSuffix(entry, "]") {
+		entry = entry[1 : len(entry)-1]
+	}
+
+	return strings.ToLower(entry)
+}
+
+// New creates a Filter from the given configuration. It returns a nil Filter,
+// which allows everything, when no patterns are configured.
+func New(cfg *Configuration) (f *Filter, err error) {
+	include := append([]string{}, cfg.Match...)
+	exclude := append([]string{}, cfg.Filter...)
+
+	for _, path := range cfg.ScopeFiles {
+		var patterns []string
+
+		patterns, err = ReadScopeFile(path)
+		if err != nil {
+			return
+		}
+
+		include = append(include, patterns...)
+	}
+
+	for _, path := range cfg.OutOfScopeFiles {
+		var patterns []string
+
+		patterns, err = ReadScopeFile(path)
+		if err != nil {
+			return
+		}
+
+		exclude = append(exclude, patterns...)
+	}
+
+	if len(include) == 0 && len(exclude) == 0 {
+		return
+	}
+
+	f = &Filter{}
+
+	for _, pattern := range include {
+		var regex *regexp.Regexp
+
+		regex, err = Compile(pattern)
+		if err != nil {
+			err = fmt.Errorf("invalid match pattern %q: %w", pattern, err)
+
+			return
+		}
+
+		f.include = append(f.include, regex)
+	}
+
+	for _, pattern := range exclude {
+		var regex *regexp.Regexp
+
+		regex, err = Compile(pattern)
+		if err != nil {
+			err = fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+
+			return
+		}
+
+		f.exclude = append(f.exclude, regex)
+	}
+
+	return
+}
@@ -0,0 +1,114 @@
+package filter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/filter"
+)
+
+func TestFilterGlob(t *testing.T) {
+	f, err := filter.New(&filter.Configuration{
+		Match:  []string{"*.api.*", "www.example.com"},
+		Filter: []string{"dev?.api.example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := map[string]bool{
+		"v1.api.example.com":   true,
+		"a.b.API.example.com":  true,
+		"WWW.example.com":      true,
+		"dev1.api.example.com": false,
+		"api.example.com":      false,
+		"shop.example.com":     false,
+		"www.example.com.evil": false,
+	}
+
+	for subdomain, want := range allowed {
+		if got := f.Allow(subdomain); got != want {
+			t.Errorf("Allow(%q) = %t, want %t", subdomain, got, want)
+		}
+	}
+}
+
+func TestFilterRegex(t *testing.T) {
+	f, err := filter.New(&filter.Configuration{
+		Match: []string{`regex:^a{1,3}\.`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := map[string]bool{
+		"a.example.com":    true,
+		"AAA.example.com":  true,
+		"aaaa.example.com": false,
+		"b.example.com":    false,
+	}
+
+	for subdomain, want := range allowed {
+		if got := f.Allow(subdomain); got != want {
+			t.Errorf("Allow(%q) = %t, want %t", subdomain, got, want)
+		}
+	}
+
+	if _, err = filter.New(&filter.Configuration{Filter: []string{"regex:("}}); err == nil {
+		t.Error("invalid regular expression accepted")
+	}
+}
+
+func TestFilterScopeFiles(t *testing.T) {
+	directory := t.TempDir()
+
+	scope := filepath.Join(directory, "scope.txt")
+	outOfScope := filepath.Join(directory, "out-of-scope.txt")
+
+	if err := os.WriteFile(scope, []byte("# in scope\nhttps://*.example.com/\n\nexample.org:443\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(outOfScope, []byte("*.corp.example.com\nregex:^legacy\\.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := filter.New(&filter.Configuration{
+		ScopeFiles:      []string{scope},
+		OutOfScopeFiles: []string{outOfScope},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := map[string]bool{
+		"www.example.com":      true,
+		"example.org":          true,
+		"vpn.corp.example.com": false,
+		"legacy.example.com":   false,
+		"www.example.org":      false,
+		"example.net":          false,
+	}
+
+	for subdomain, want := range allowed {
+		if got := f.Allow(subdomain); got != want {
+			t.Errorf("Allow(%q) = %t, want %t", subdomain, got, want)
+		}
+	}
+}
+
+func TestFilterNone(t *testing.T) {
+	f, err := filter.New(&filter.Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f != nil {
+		t.Fatal("filter created without patterns")
+	}
+
+	if !f.Allow("www.example.com") {
+		t.Error("nil filter drops subdomains")
+	}
+}
@@ -0,0 +1,261 @@
+package graph
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// Graph aggregates the results of runs into a graph of the namespace of their
+// root domains: the label hierarchy of the names found, which sources found
+// them, the canonical names they point to and the IP addresses they resolve
+// to. It is safe for concurrent use.
+type Graph struct {
+	mutex sync.Mutex
+
+	nodes map[string]*Node
+	edges map[Edge]struct{}
+}
+
+// Node is a node of the graph.
+type Node struct {
+	ID    string `json:"id"`    // The identifier of the node, its kind and label, e.g. "name:www.example.com".
+	Label string `json:"label"` // The name, source or IP address the node is.
+	Kind  string `json:"kind"`  // The kind of the node, one of the Kind constants.
+}
+
+// Edge is a directed edge of the graph.
+type Edge struct {
+	Source string `json:"source"` // The identifier of the node the edge starts from.
+	Target string `json:"target"` // The identifier of the node the edge ends at.
+	Kind   string `json:"kind"`   // The kind of the edge, one of the Kind constants.
+}
+
+// Kinds of nodes.
+const (
+	KindRoot      = "root"      // A root domain enumerated.
+	KindSubdomain = "subdomain" // A subdomain found.
+	KindLabel     = "label"     // A name of the hierarchy of subdomains found, not found itself.
+	KindExternal  = "external"  // A name outside of the root domains, e.g. a canonical name.
+	KindSource    = "source"    // A source or stage.
+	KindIP        = "ip"        // An IP address.
+)
+
+// Kinds of edges.
+const (
+	KindChild    = "child"    // From a name to a name one label longer.
+	KindFound    = "found"    // From a source to a subdomain it found.
+	KindCNAME    = "cname"    // From a name to its canonical name.
+	KindResolves = "resolves" // From a subdomain to an IP address of it.
+)
+
+// Formats of the files written by WriteFile.
+const (
+	FormatDOT     = "dot"
+	FormatGraphML = "graphml"
+	FormatJSON    = "json"
+)
+
+// ErrUnknownFormat is returned for paths of files whose extension is none of
+// those of the supported formats.
+var ErrUnknownFormat = errors.New("unknown graph format, use a .dot, .gv, .graphml or .json file")
+
+// Add adds result, found for the root domain domain, to the graph. Only
+// subdomains, attributions, IP addresses and CNAMEs are added.
+func (g *Graph) Add(domain string, result sources.Result) {
+	g.mutex.Lock()
+
+	defer g.mutex.Unlock()
+
+	switch result.Type {
+	case sources.ResultSubdomain, sources.ResultAttribution:
+		name := g.name(domain, result.Value)
+
+		if g.nodes[name].Kind != KindRoot {
+			g.nodes[name].Kind = KindSubdomain
+		}
+
+		g.edge(g.node(KindSource, result.Source), name, KindFound)
+
+		for _, address := range result.Addresses {
+			g.edge(name, g.node(KindIP, address.IP), KindResolves)
+		}
+	case sources.ResultIP:
+		name := g.name(domain, result.Subdomain)
+
+		g.edge(name, g.node(KindIP, result.Value), KindResolves)
+	case sources.ResultCNAME:
+		g.edge(g.name(domain, result.Subdomain), g.name(domain, result.Value), KindCNAME)
+	}
+}
+
+// name adds the node of the name name, and, for names under domain, those of
+// the hierarchy from domain down to it. It returns its identifier.
+func (g *Graph) name(domain, name string) (id string) {
+	id = "name:" + name
+
+	if name != domain && !strings.HasSuffix(name, "."+domain) {
+		if _, ok := g.nodes[id]; !ok {
+			g.nodes[id] = &Node{ID: id, Label: name, Kind: KindExternal}
+		}
+
+		return
+	}
+
+	child := ""
+
+	for current := name; ; {
+		currentID := "name:" + current
+
+		node, ok := g.nodes[currentID]
+		if !ok {
+			node = &Node{ID: currentID, Label: current, Kind: KindLabel}
+
+			g.nodes[currentID] = node
+		}
+
+		if current == domain {
+			node.Kind = KindRoot
+		}
+
+		if child != "" {
+			g.edges[Edge{Source: currentID, Target: child, Kind: KindChild}] = struct{}{}
+		}
+
+		// The rest of the hierarchy is known from the first name known.
+		if ok || current == domain {
+			break
+		}
+
+		child = currentID
+
+		_, current, _ = strings.Cut(current, ".")
+	}
+
+	return
+}
+
+// node adds the node of kind labeled label, if new, and returns its identifier.
+func (g *Graph) node(kind, label string) (id string) {
+	id = kind + ":" + label
+
+	if _, ok := g.nodes[id]; !ok {
+		g.nodes[id] = &Node{ID: id, Label: label, Kind: kind}
+	}
+
+	return
+}
+
+func (g *Graph) edge(source, target, kind string) {
+	g.edges[Edge{Source: source, Target: target, Kind: kind}] = struct{}{}
+}
+
+// Nodes returns the nodes of the graph, sorted by identifier.
+func (g *Graph) Nodes() (nodes []Node) {
+	g.mutex.Lock()
+
+	defer g.mutex.Unlock()
+
+	nodes = make([]Node, 0, len(g.nodes))
+
+	for _, node := range g.nodes {
+		nodes = append(nodes, *node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return
+}
+
+// Edges returns the edges of the graph, sorted by source, target and kind.
+func (g *Graph) Edges() (edges []Edge) {
+	g.mutex.Lock()
+
+	defer g.mutex.Unlock()
+
+	edges = make([]Edge, 0, len(g.edges))
+
+	for edge := range g.edges {
+		edges = append(edges, edge)
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+
+		if edges[i].Target != edges[j].Target {
+			return edges[i].Target < edges[j].Target
+		}
+
+		return edges[i].Kind < edges[j].Kind
+	})
+
+	return
+}
+
+// FormatOf returns the format of the graph file at path, by its extension.
+func FormatOf(path string) (format string, err error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		format = FormatDOT
+	case ".graphml":
+		format = FormatGraphML
+	case ".json":
+		format = FormatJSON
+	default:
+		err = ErrUnknownFormat
+	}
+
+	return
+}
+
+// WriteFile writes the graph to the file at path, replacing it, in the format
+// of its extension.
+func (g *Graph) WriteFile(path string) (err error) {
+	var format string
+
+	format, err = FormatOf(path)
+	if err != nil {
+		return
+	}
+
+	var file *os.File
+
+	file, err = os.Create(path)
+	if err != nil {
+		return
+	}
+
+	switch format {
+	case FormatDOT:
+		err = g.WriteDOT(file)
+	case FormatGraphML:
+		err = g.WriteGraphML(file)
+	case FormatJSON:
+		err = g.WriteJSON(file)
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return
+}
+
+// New creates an empty Graph.
+func New() (g *Graph) {
+	g = &Graph{
+		nodes: map[string]*Node{},
+		edges: map[Edge]struct{}{},
+	}
+
+	return
+}
@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dotStyles are the DOT attributes of nodes and edges, by kind.
+var dotStyles = map[string]string{
+	KindRoot:      `shape=box, style="bold,filled", fillcolor="#ffd966"`,
+	KindSubdomain: `shape=box`,
+	KindLabel:     `shape=box, style=dashed`,
+	KindExternal:  `shape=box, style=dotted`,
+	KindSource:    `shape=ellipse, style=filled, fillcolor="#cfe2f3"`,
+	KindIP:        `shape=diamond`,
+	KindChild:     `color="#999999"`,
+	KindFound:     `style=dashed, color="#6fa8dc"`,
+	KindCNAME:     `color="#e06666", label="CNAME"`,
+	KindResolves:  `style=dotted`,
+}
+
+// WriteDOT writes the graph to w in the Graphviz DOT language.
+func (g *Graph) WriteDOT(w io.Writer) (err error) {
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintln(writer, "digraph xsubfind3r {")
+	fmt.Fprintln(writer, "\trankdir=LR;")
+
+	for _, node := range g.Nodes() {
+		fmt.Fprintf(writer, "\t%s [label=%s, kind=%s, %s];\n", dotQuote(node.ID), dotQuote(node.Label), dotQuote(node.Kind), dotStyles[node.Kind])
+	}
+
+	for _, edge := range g.Edges() {
+		fmt.Fprintf(writer, "\t%s -> %s [kind=%s, %s];\n", dotQuote(edge.Source), dotQuote(edge.Target), dotQuote(edge.Kind), dotStyles[edge.Kind])
+	}
+
+	fmt.Fprintln(writer, "}")
+
+	return writer.Flush()
+}
+
+// dotQuote returns value as a quoted DOT identifier.
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the graph to w in GraphML, the labels and kinds of nodes
+// and edges as data.
+func (g *Graph) WriteGraphML(w io.Writer) (err error) {
+	document := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "edge_kind", For: "edge", Name: "kind", Type: "string"},
+		},
+	}
+
+	document.Graph.ID = "xsubfind3r"
+	document.Graph.EdgeDefault = "directed"
+
+	for _, node := range g.Nodes() {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: node.Label},
+				{Key: "kind", Value: node.Kind},
+			},
+		})
+	}
+
+	for _, edge := range g.Edges() {
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Data: []graphMLData{
+				{Key: "edge_kind", Value: edge.Kind},
+			},
+		})
+	}
+
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return
+	}
+
+	encoder := xml.NewEncoder(w)
+
+	encoder.Indent("", "\t")
+
+	if err = encoder.Encode(document); err != nil {
+		return
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return
+}
+
+// WriteJSON writes the graph to w as a JSON object of nodes and edges, e.g.
+// {"nodes":[{"id":"name:example.com","label":"example.com","kind":"root"}],"edges":[]}.
+func (g *Graph) WriteJSON(w io.Writer) (err error) {
+	document := struct {
+		Nodes []Node `json:"nodes"`
+		Edges []Edge `json:"edges"`
+	}{
+		Nodes: g.Nodes(),
+		Edges: g.Edges(),
+	}
+
+	encoder := json.NewEncoder(w)
+
+	encoder.SetIndent("", "\t")
+
+	return encoder.Encode(document)
+}
@@ -0,0 +1,241 @@
+package normalizer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// Normalizer turns raw candidates reported by sources into canonical subdomains,
+// rejecting those that are not valid hostnames under the target domain.
+//
+// Candidates go through the following steps, in order:
+//   - surrounding whitespace is trimmed and percent-encoding (e.g. `%2f`) is decoded.
+//   - leftovers of the surrounding text are cut: when the candidate is a URL-like
+//     token (e.g. `https://user@api.example.com:443/path`), the part naming a
+//     host under the target is kept, and JSON escapes such as `u002f` glued to
+//     the first label are removed.
+//   - the candidate is lowercased, wildcard labels (`*.`) and leading dots or
+//     dashes are removed, and trailing dots are stripped.
+//   - internationalized names are converted to their punycode (`xn--`) form.
+//   - the result is validated against RFC 1035 label rules, as relaxed by
+//     RFC 1123 to allow labels starting with a digit, and by service names
+//     to allow underscores (e.g. `_dmarc`, `_sip._tcp`), and checked to be in scope.
+//   - optionally, a leading `www.` label is removed to fold it onto its parent.
+type Normalizer struct {
+	stripWWW bool
+}
+
+// Configuration holds the options of a Normalizer.
+type Configuration struct {
+	// StripWWW folds `www.<name>` onto `<name>`, removing www-style duplicates.
+	StripWWW bool
+}
+
+// RejectionError is returned for candidates that cannot be normalized into a
+// valid in-scope subdomain. It carries the rejected candidate and the reason.
+type RejectionError struct {
+	Candidate string
+	Reason    string
+}
+
+func (e *RejectionError) Error() string {
+	return fmt.Sprintf("rejected %q: %s", e.Candidate, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrRejected) true for any RejectionError.
+func (e *RejectionError) Is(target error) bool {
+	return target == ErrRejected
+}
+
+const (
+	maxNameLength  = 253
+	maxLabelLength = 63
+)
+
+var (
+	// escapeLeftoverRegex matches JSON (`\u002f`) and hex (`\x2f`) escapes left
+	// glued to the start of a name after the Extractor cut them mid-token.
+	escapeLeftoverRegex = regexp.MustCompile(`^(?:\\?u00[0-9a-f]{2}|\\x[0-9a-f]{2})+`)
+
+	profile = idna.New(
+		idna.MapForLookup(),
+		idna.Transitional(false),
+		idna.StrictDomainName(false),
+	)
+
+	ErrRejected = errors.New("candidate rejected")
+)
+
+// Normalize normalizes candidate and validates it is a subdomain of domain,
+// or domain itself. On failure, it returns a *RejectionError with the reason.
+// domain is expected in its punycode form, as returned by Domain.
+func (n *Normalizer) Normalize(candidate, domain string) (subdomain string, err error) {
+	reject := func(reason string, args ...interface{}) {
+		err = &RejectionError{
+			Candidate: candidate,
+			Reason:    fmt.Sprintf(reason, args...),
+		}
+	}
+
+	subdomain = strings.TrimSpace(candidate)
+
+	if strings.Contains(subdomain, "%") {
+		if decoded, unescapeErr := url.PathUnescape(subdomain); unescapeErr == nil {
+			subdomain = decoded
+		}
+	}
+
+	subdomain = strings.ToLower(subdomain)
+
+	subdomain = host(subdomain, domain)
+
+	subdomain = escapeLeftoverRegex.ReplaceAllString(subdomain, "")
+
+	for strings.HasPrefix(subdomain, "*.") {
+		subdomain = subdomain[2:]
+	}
+
+	subdomain = strings.TrimLeft(subdomain, ".-")
+	subdomain = strings.TrimRight(subdomain, ".")
+
+	if subdomain == "" {
+		reject("empty after normalization")
+
+		return
+	}
+
+	if !isASCII(subdomain) {
+		var ascii string
+
+		ascii, err = profile.ToASCII(subdomain)
+		if err != nil {
+			reject("invalid internationalized name: %s", err)
+
+			return
+		}
+
+		subdomain = ascii
+	}
+
+	if reason := validate(subdomain); reason != "" {
+		reject(reason)
+
+		return
+	}
+
+	if subdomain != domain && !strings.HasSuffix(subdomain, "."+domain) {
+		reject("not under %s", domain)
+
+		return
+	}
+
+	if n.stripWWW {
+		if stripped, ok := strings.CutPrefix(subdomain, "www."); ok && (stripped == domain || strings.HasSuffix(stripped, "."+domain)) {
+			subdomain = stripped
+		}
+	}
+
+	return
+}
+
+// host picks, out of a URL-like token, the part that names a host under domain.
+// Tokens are split on URL delimiters and ports are dropped; if no part is under
+// domain, the token is returned unchanged so that it is rejected with a reason.
+func host(token, domain string) string {
+	if !strings.ContainsAny(token, "/@=?#:") {
+		return token
+	}
+
+	parts := strings.FieldsFunc(token, func(r rune) bool {
+		return strings.ContainsRune("/@=?#&", r)
+	})
+
+	for index := len(parts) - 1; index >= 0; index-- {
+		part, _, _ := strings.Cut(parts[index], ":")
+
+		part = strings.TrimRight(part, ".")
+
+		if part == domain || strings.HasSuffix(part, "."+domain) {
+			return part
+		}
+	}
+
+	return token
+}
+
+// validate checks name against RFC 1035 label rules, as relaxed by RFC 1123
+// and to allow underscores, returning the reason it is invalid or an empty string if it is valid.
+func validate(name string) (reason string) {
+	if len(name) > maxNameLength {
+		reason = fmt.Sprintf("name longer than %d characters", maxNameLength)
+
+		return
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		switch {
+		case label == "":
+			reason = "empty label"
+		case len(label) > maxLabelLength:
+			reason = fmt.Sprintf("label %q longer than %d characters", label, maxLabelLength)
+		case label[0] == '-' || label[len(label)-1] == '-':
+			reason = fmt.Sprintf("label %q starts or ends with a hyphen", label)
+		default:
+			for _, r := range label {
+				if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+					reason = fmt.Sprintf("label %q contains invalid character %q", label, r)
+
+					break
+				}
+			}
+		}
+
+		if reason != "" {
+			return
+		}
+	}
+
+	return
+}
+
+// Domain returns domain lowercased and, if internationalized, in its punycode
+// (`xn--`) form, that of the subdomains Normalize returns. Names that cannot be
+// converted are returned lowercased only.
+func Domain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	if isASCII(domain) {
+		return domain
+	}
+
+	if ascii, err := profile.ToASCII(domain); err == nil {
+		domain = ascii
+	}
+
+	return domain
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// New creates a Normalizer with the given configuration.
+func New(cfg *Configuration) (n *Normalizer) {
+	n = &Normalizer{
+		stripWWW: cfg.StripWWW,
+	}
+
+	return
+}
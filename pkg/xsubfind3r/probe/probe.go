@@ -0,0 +1,247 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/internal/configuration"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/cluster"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// Prober checks the web servers of subdomains over HTTP and HTTPS, on the
+// configured ports, following redirects within limits. It is safe for
+// concurrent use.
+type Prober struct {
+	client *http.Client
+
+	httpPorts  []int
+	httpsPorts []int
+
+	maxSize     int64
+	concurrency int
+}
+
+// NAME is the source of the results of probing.
+const NAME = "probe"
+
+const (
+	defaultMaxRedirects = 5
+	defaultMaxSize      = 1 << 20
+	defaultConcurrency  = 50
+	defaultTimeout      = 10 * time.Second
+)
+
+var (
+	// defaultHTTPPorts and defaultHTTPSPorts are used when no ports are configured.
+	defaultHTTPPorts  = []int{80}
+	defaultHTTPSPorts = []int{443}
+
+	// titleRegex matches the title of an HTML page.
+	titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// Probe probes each of subdomains on every configured port, with at most the
+// configured number of probes at a time, and sends a result down results for
+// each web server that answered. It returns once all probes returned or ctx
+// is canceled.
+func (p *Prober) Probe(ctx context.Context, subdomains []string, results chan sources.Result) {
+	type target struct {
+		subdomain string
+		url       string
+	}
+
+	targets := make(chan target)
+
+	wg := &sync.WaitGroup{}
+
+	for range p.concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for t := range targets {
+				response, err := p.fetch(ctx, t.url)
+				if err != nil {
+					continue
+				}
+
+				results <- sources.Result{
+					Type:      sources.ResultProbe,
+					Source:    NAME,
+					Value:     t.url,
+					Subdomain: t.subdomain,
+					Response:  response,
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, subdomain := range subdomains {
+		for _, url := range p.urls(subdomain) {
+			select {
+			case <-ctx.Done():
+				break feed
+			case targets <- target{subdomain: subdomain, url: url}:
+			}
+		}
+	}
+
+	close(targets)
+
+	wg.Wait()
+}
+
+// urls returns the URLs probed for subdomain, HTTPS first, leaving default
+// ports out.
+func (p *Prober) urls(subdomain string) (urls []string) {
+	for _, scheme := range []struct {
+		name        string
+		ports       []int
+		defaultPort int
+	}{
+		{"https", p.httpsPorts, 443},
+		{"http", p.httpPorts, 80},
+	} {
+		for _, port := range scheme.ports {
+			host := subdomain
+
+			if port != scheme.defaultPort {
+				host = net.JoinHostPort(subdomain, strconv.Itoa(port))
+			}
+
+			urls = append(urls, scheme.name+"://"+host+"/")
+		}
+	}
+
+	return
+}
+
+// fetch requests url, following redirects, and describes the final response.
+func (p *Prober) fetch(ctx context.Context, url string) (response *sources.Response, err error) {
+	var req *http.Request
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("User-Agent", fmt.Sprintf("%s v%s (https://github.com/hueristiq/%s)", configuration.NAME, configuration.VERSION, configuration.NAME))
+
+	var res *http.Response
+
+	res, err = p.client.Do(req)
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	var body []byte
+
+	body, err = io.ReadAll(io.LimitReader(res.Body, p.maxSize))
+	if err != nil {
+		return
+	}
+
+	response = &sources.Response{
+		StatusCode:    res.StatusCode,
+		Title:         title(body),
+		ContentLength: res.ContentLength,
+		Server:        res.Header.Get("Server"),
+		FinalURL:      res.Request.URL.String(),
+
+		SimHash:           cluster.SimHash(body),
+		HeaderFingerprint: cluster.HeaderFingerprint(res.Header),
+	}
+
+	// Chunked and compressed responses have no known length, the body read is.
+	if response.ContentLength < 0 {
+		response.ContentLength = int64(len(body))
+	}
+
+	return
+}
+
+// title returns the title of an HTML page, unescaped and with its whitespace
+// collapsed, or an empty string if it has none.
+func title(body []byte) string {
+	match := titleRegex.FindSubmatch(body)
+
+	if match == nil {
+		return ""
+	}
+
+	return strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " ")
+}
+
+// New creates a Prober from cfg, using defaults for options left unset.
+func New(cfg *sources.ProbeConfiguration) (p *Prober, err error) {
+	p = &Prober{
+		httpPorts:   cfg.HTTPPorts,
+		httpsPorts:  cfg.HTTPSPorts,
+		maxSize:     cfg.MaxSize,
+		concurrency: cfg.Concurrency,
+	}
+
+	if len(p.httpPorts) == 0 && len(p.httpsPorts) == 0 {
+		p.httpPorts = defaultHTTPPorts
+		p.httpsPorts = defaultHTTPSPorts
+	}
+
+	if p.maxSize < 1 {
+		p.maxSize = defaultMaxSize
+	}
+
+	if p.concurrency < 1 {
+		p.concurrency = defaultConcurrency
+	}
+
+	maxRedirects := defaultMaxRedirects
+
+	if cfg.MaxRedirects != nil {
+		maxRedirects = max(0, *cfg.MaxRedirects)
+	}
+
+	timeout := defaultTimeout
+
+	if cfg.Timeout != "" {
+		timeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return
+		}
+	}
+
+	p.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true, //nolint:gosec // Servers are probed, not trusted.
+				MinVersion:         tls.VersionTLS10,
+			},
+		},
+		// Past the limit, the last redirect is described instead of followed.
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return http.ErrUseLastResponse
+			}
+
+			return nil
+		},
+	}
+
+	return
+}
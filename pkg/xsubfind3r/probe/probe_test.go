@@ -0,0 +1,56 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+func TestFetchFollowsRedirectsUpToMaxRedirects(t *testing.T) {
+	// /0 redirects to /1, which redirects to /2, and so on up to /3.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hop, _ := strconv.Atoi(r.URL.Path[1:])
+
+		if hop < 3 {
+			http.Redirect(w, r, "/"+strconv.Itoa(hop+1), http.StatusFound)
+
+			return
+		}
+
+		w.Write([]byte("<title>Done</title>"))
+	}))
+
+	t.Cleanup(server.Close)
+
+	none, two := 0, 2
+
+	tests := []struct {
+		maxRedirects *int
+		status       int
+		path         string
+	}{
+		{nil, http.StatusOK, "/3"},
+		{&none, http.StatusFound, "/0"},
+		{&two, http.StatusFound, "/2"},
+	}
+
+	for index, test := range tests {
+		p, err := New(&sources.ProbeConfiguration{MaxRedirects: test.maxRedirects})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response, err := p.fetch(context.Background(), server.URL+"/0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.StatusCode != test.status || response.FinalURL != server.URL+test.path {
+			t.Errorf("test %d: response = %d %s, want %d %s", index, response.StatusCode, response.FinalURL, test.status, server.URL+test.path)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package anubis
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -10,7 +11,7 @@ import (
 
 type Source struct{}
 
-func (source *Source) Run(_ *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, _ *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -18,7 +19,7 @@ func (source *Source) Run(_ *sources.Configuration, domain string) <-chan source
 
 		getSubdomainsReqURL := fmt.Sprintf("https://jldc.me/anubis/subdomains/%s", domain)
 
-		getSubdomainsRes, err := httpclient.SimpleGet(getSubdomainsReqURL)
+		getSubdomainsRes, err := httpclient.SimpleGet(ctx, getSubdomainsReqURL)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
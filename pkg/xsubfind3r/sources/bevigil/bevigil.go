@@ -1,6 +1,7 @@
 package bevigil
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -15,7 +16,7 @@ type getSubdomainsResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -39,7 +40,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 			"X-Access-Token": key,
 		}
 
-		getSubdomainsRes, err := httpclient.Get(getSubdomainsReqURL, "", getSubdomainsReqHeaders)
+		getSubdomainsRes, err := httpclient.Get(ctx, getSubdomainsReqURL, "", getSubdomainsReqHeaders)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
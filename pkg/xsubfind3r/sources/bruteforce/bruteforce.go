@@ -0,0 +1,118 @@
+package bruteforce
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/wordlist"
+)
+
+type Source struct{}
+
+// defaultConcurrency is used when no concurrency is configured.
+const defaultConcurrency = 50
+
+var ErrNoResolver = errors.New("no resolver configured")
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		if cfg.Resolver == nil {
+			source.error(ErrNoResolver, results)
+
+			return
+		}
+
+		words, err := wordlist.Read(cfg.Bruteforce.Wordlist)
+		if err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		concurrency := cfg.Bruteforce.Concurrency
+
+		if concurrency < 1 {
+			concurrency = defaultConcurrency
+		}
+
+		wildcards := resolver.NewWildcards(cfg.Resolver)
+
+		names := make(chan string)
+
+		// Failed lookups, mostly timeouts, are reported once, as a count.
+		failures := &resolver.Failures{}
+
+		wg := &sync.WaitGroup{}
+
+		for range concurrency {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for name := range names {
+					addresses, err := cfg.Resolver.LookupHost(ctx, name)
+					if err != nil {
+						if ctx.Err() == nil {
+							failures.Add(err)
+						}
+
+						continue
+					}
+
+					if len(addresses) == 0 || wildcards.IsWildcard(ctx, domain, name, addresses) {
+						continue
+					}
+
+					result := sources.Result{
+						Type:   sources.ResultSubdomain,
+						Source: source.Name(),
+						Value:  name,
+					}
+
+					results <- result
+				}
+			}()
+		}
+
+	feed:
+		for _, word := range words {
+			select {
+			case <-ctx.Done():
+				break feed
+			case names <- word + "." + domain:
+			}
+		}
+
+		close(names)
+
+		wg.Wait()
+
+		if err := failures.Err(); err != nil {
+			source.error(err, results)
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.BRUTEFORCE
+}
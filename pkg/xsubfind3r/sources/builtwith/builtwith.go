@@ -1,6 +1,7 @@
 package builtwith
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -26,7 +27,7 @@ type getDomainInfoResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -47,7 +48,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 
 		getDomainInfoReqURL := fmt.Sprintf("https://api.builtwith.com/v21/api.json?KEY=%s&HIDETEXT=yes&HIDEDL=yes&NOLIVE=yes&NOMETA=yes&NOPII=yes&NOATTR=yes&LOOKUP=%s", key, domain)
 
-		getDomainInfoRes, err := httpclient.SimpleGet(getDomainInfoReqURL)
+		getDomainInfoRes, err := httpclient.SimpleGet(ctx, getDomainInfoReqURL)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
@@ -1,6 +1,7 @@
 package censys
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -37,7 +38,7 @@ type certSearchResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -71,7 +72,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 				certSearchReqURL = certSearchReqURL + "&cursor=" + cursor
 			}
 
-			certSearchRes, err := httpclient.Get(certSearchReqURL, "", certSearchReqHeaders)
+			certSearchRes, err := httpclient.Get(ctx, certSearchReqURL, "", certSearchReqHeaders)
 			if err != nil {
 				result := sources.Result{
 					Type:   sources.ResultError,
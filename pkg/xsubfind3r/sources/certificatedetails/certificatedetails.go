@@ -2,6 +2,7 @@ package certificatedetails
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 
 	"github.com/hueristiq/hq-go-http/status"
@@ -11,7 +12,7 @@ import (
 
 type Source struct{}
 
-func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -19,7 +20,7 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 
 		getCertificateDetailsReqURL := fmt.Sprintf("https://certificatedetails.com/%s", domain)
 
-		getCertificateDetailsRes, err := httpclient.SimpleGet(getCertificateDetailsReqURL)
+		getCertificateDetailsRes, err := httpclient.SimpleGet(ctx, getCertificateDetailsReqURL)
 		if err != nil && getCertificateDetailsRes.StatusCode != status.NotFound {
 			result := sources.Result{
 				Type:   sources.ResultError,
@@ -1,6 +1,7 @@
 package certspotter
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -16,7 +17,7 @@ type getCTLogsSearchResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -35,70 +36,78 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 			return
 		}
 
-		getCTLogsSearchReqURL := fmt.Sprintf("https://api.certspotter.com/v1/issuances?domain=%s&include_subdomains=true&expand=dns_names", domain)
 		getCTLogsSearchReqHeaders := map[string]string{
 			"Authorization": "Bearer " + key,
 		}
 
-		getCTLogsSearchRes, err := httpclient.Get(getCTLogsSearchReqURL, "", getCTLogsSearchReqHeaders)
-		if err != nil {
-			result := sources.Result{
-				Type:   sources.ResultError,
-				Source: source.Name(),
-				Error:  err,
-			}
+		// A resumed run continues after the last issuance it processed.
+		id := config.Cursor(domain, source.Name())
 
-			results <- result
+		if id == "" {
+			getCTLogsSearchReqURL := fmt.Sprintf("https://api.certspotter.com/v1/issuances?domain=%s&include_subdomains=true&expand=dns_names", domain)
 
-			httpclient.DiscardResponse(getCTLogsSearchRes)
+			getCTLogsSearchRes, err := httpclient.Get(ctx, getCTLogsSearchReqURL, "", getCTLogsSearchReqHeaders)
+			if err != nil {
+				result := sources.Result{
+					Type:   sources.ResultError,
+					Source: source.Name(),
+					Error:  err,
+				}
 
-			return
-		}
+				results <- result
 
-		var getCTLogsSearchResData []getCTLogsSearchResponse
+				httpclient.DiscardResponse(getCTLogsSearchRes)
 
-		if err = json.NewDecoder(getCTLogsSearchRes.Body).Decode(&getCTLogsSearchResData); err != nil {
-			result := sources.Result{
-				Type:   sources.ResultError,
-				Source: source.Name(),
-				Error:  err,
+				return
 			}
 
-			results <- result
+			var getCTLogsSearchResData []getCTLogsSearchResponse
+
+			if err = json.NewDecoder(getCTLogsSearchRes.Body).Decode(&getCTLogsSearchResData); err != nil {
+				result := sources.Result{
+					Type:   sources.ResultError,
+					Source: source.Name(),
+					Error:  err,
+				}
+
+				results <- result
+
+				getCTLogsSearchRes.Body.Close()
+
+				return
+			}
 
 			getCTLogsSearchRes.Body.Close()
 
-			return
-		}
+			if len(getCTLogsSearchResData) == 0 {
+				return
+			}
 
-		getCTLogsSearchRes.Body.Close()
+			for _, cert := range getCTLogsSearchResData {
+				for _, subdomain := range cert.DNSNames {
+					if subdomain != domain && !strings.HasSuffix(subdomain, "."+domain) {
+						continue
+					}
 
-		if len(getCTLogsSearchResData) == 0 {
-			return
-		}
+					result := sources.Result{
+						Type:   sources.ResultSubdomain,
+						Source: source.Name(),
+						Value:  subdomain,
+					}
 
-		for _, cert := range getCTLogsSearchResData {
-			for _, subdomain := range cert.DNSNames {
-				if subdomain != domain && !strings.HasSuffix(subdomain, "."+domain) {
-					continue
+					results <- result
 				}
+			}
 
-				result := sources.Result{
-					Type:   sources.ResultSubdomain,
-					Source: source.Name(),
-					Value:  subdomain,
-				}
+			id = getCTLogsSearchResData[len(getCTLogsSearchResData)-1].ID
 
-				results <- result
-			}
+			config.SetCursor(domain, source.Name(), id)
 		}
 
-		id := getCTLogsSearchResData[len(getCTLogsSearchResData)-1].ID
-
 		for {
 			getCTLogsSearchReqURL := fmt.Sprintf("https://api.certspotter.com/v1/issuances?domain=%s&include_subdomains=true&expand=dns_names&after=%s", domain, id)
 
-			getCTLogsSearchRes, err := httpclient.Get(getCTLogsSearchReqURL, "", getCTLogsSearchReqHeaders)
+			getCTLogsSearchRes, err := httpclient.Get(ctx, getCTLogsSearchReqURL, "", getCTLogsSearchReqHeaders)
 			if err != nil {
 				result := sources.Result{
 					Type:   sources.ResultError,
@@ -152,6 +161,8 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 			}
 
 			id = getCTLogsSearchResData[len(getCTLogsSearchResData)-1].ID
+
+			config.SetCursor(domain, source.Name(), id)
 		}
 	}()
 
@@ -0,0 +1,178 @@
+package circl
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// record is a line of the Passive DNS - Common Output Format (COF) served by CIRCL.
+type record struct {
+	RRName    string `json:"rrname"`
+	RRType    string `json:"rrtype"`
+	RData     string `json:"rdata"`
+	TimeFirst int64  `json:"time_first"`
+	TimeLast  int64  `json:"time_last"`
+}
+
+type Source struct{}
+
+const (
+	// pageSize is the number of records requested per page.
+	pageSize        = 1000
+	defaultMaxPages = 10
+	// pagingCursorHeader carries the cursor of the next page, in requests and responses.
+	pagingCursorHeader = "Dribble-Paging-Cursor"
+)
+
+var ErrMalformedKey = errors.New("malformed key, expected `username:password`")
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		key, err := cfg.Keys.CIRCL.PickRandom()
+		if key == "" || err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		username, password, ok := strings.Cut(key, ":")
+		if !ok || username == "" || password == "" {
+			source.error(ErrMalformedKey, results)
+
+			return
+		}
+
+		// CIRCL takes no time range, records last seen before the fence are dropped here.
+		since, err := cfg.PassiveDNS.SinceTime()
+		if err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		maxPages := cfg.PassiveDNS.MaxPages
+
+		if maxPages < 1 {
+			maxPages = defaultMaxPages
+		}
+
+		queryReqURL := "https://www.circl.lu/pdns/query/" + domain
+
+		cursor := ""
+
+		for range maxPages {
+			if ctx.Err() != nil {
+				return
+			}
+
+			queryReqHeaders := map[string]string{
+				"Accept":               "application/json",
+				"Authorization":        "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)),
+				"Dribble-Paging-Count": strconv.Itoa(pageSize),
+			}
+
+			if cursor != "" {
+				queryReqHeaders[pagingCursorHeader] = cursor
+			}
+
+			var queryRes *http.Response
+
+			queryRes, err = httpclient.Get(ctx, queryReqURL, "", queryReqHeaders)
+			if err != nil {
+				source.error(err, results)
+
+				httpclient.DiscardResponse(queryRes)
+
+				return
+			}
+
+			scanner := bufio.NewScanner(queryRes.Body)
+
+			for scanner.Scan() {
+				var r record
+
+				if err = json.Unmarshal(scanner.Bytes(), &r); err != nil {
+					source.error(err, results)
+
+					continue
+				}
+
+				lastSeen := time.Unix(r.TimeLast, 0).UTC()
+
+				if !since.IsZero() && lastSeen.Before(since) {
+					continue
+				}
+
+				names := []string{r.RRName}
+
+				// Records pointing to names, e.g. CNAME, may point to other subdomains.
+				switch strings.ToUpper(r.RRType) {
+				case "CNAME", "NS", "MX", "PTR":
+					names = append(names, r.RData)
+				}
+
+				for _, name := range names {
+					name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+					if name != domain && !strings.HasSuffix(name, "."+domain) {
+						continue
+					}
+
+					result := sources.Result{
+						Type:      sources.ResultSubdomain,
+						Source:    source.Name(),
+						Value:     name,
+						FirstSeen: time.Unix(r.TimeFirst, 0).UTC(),
+						LastSeen:  lastSeen,
+					}
+
+					results <- result
+				}
+			}
+
+			if err = scanner.Err(); err != nil {
+				source.error(err, results)
+			}
+
+			queryRes.Body.Close()
+
+			next := queryRes.Header.Get(pagingCursorHeader)
+
+			if next == "" || next == cursor || next == "0" {
+				return
+			}
+
+			cursor = next
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.CIRCL
+}
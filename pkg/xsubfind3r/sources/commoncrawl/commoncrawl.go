@@ -2,6 +2,7 @@ package commoncrawl
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -30,7 +31,7 @@ type getURLsResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -38,7 +39,7 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 
 		getIndexesReqURL := "https://index.commoncrawl.org/collinfo.json"
 
-		getIndexesRes, err := httpclient.SimpleGet(getIndexesReqURL)
+		getIndexesRes, err := httpclient.SimpleGet(ctx, getIndexesReqURL)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
@@ -99,7 +100,7 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 				"Host": "index.commoncrawl.org",
 			}
 
-			getPaginationRes, err := httpclient.SimpleGet(getPaginationReqURL)
+			getPaginationRes, err := httpclient.SimpleGet(ctx, getPaginationReqURL)
 			if err != nil {
 				result := sources.Result{
 					Type:   sources.ResultError,
@@ -139,7 +140,7 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 			for page := range getPaginationData.Pages {
 				getURLsReqURL := fmt.Sprintf("%s?url=*.%s/*&output=json&fl=url&page=%d", CCIndexAPI, domain, page)
 
-				getURLsRes, err := httpclient.Get(getURLsReqURL, "", getURLsReqHeaders)
+				getURLsRes, err := httpclient.Get(ctx, getURLsReqURL, "", getURLsReqHeaders)
 				if err != nil {
 					result := sources.Result{
 						Type:   sources.ResultError,
@@ -199,6 +200,22 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 
 						results <- result
 					}
+
+					// Crawled URLs reference other hosts, e.g. redirect targets in their query.
+					for _, host := range sources.URLHosts(getURLsResData.URL) {
+						if sources.InScope(host, domain) {
+							continue
+						}
+
+						result := sources.Result{
+							Type:     sources.ResultRelated,
+							Source:   source.Name(),
+							Value:    host,
+							Evidence: getURLsResData.URL,
+						}
+
+						results <- result
+					}
 				}
 
 				if err = scanner.Err(); err != nil {
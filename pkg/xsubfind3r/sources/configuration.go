@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"math/big"
 	"regexp"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
 )
 
 // Configuration holds the overall settings for different data sources.
@@ -14,6 +17,296 @@ type Configuration struct {
 	Extractor *regexp.Regexp
 
 	Keys Keys
+
+	// Cursors, if set, persists pagination cursors of paginated sources.
+	Cursors Cursors
+
+	// CTLog holds the settings of the `ctlog` source.
+	CTLog CTLogConfiguration
+
+	// GitLab holds the settings of the `gitlab` source.
+	GitLab GitLabConfiguration
+
+	// LocalFS holds the settings of the `localfs` source.
+	LocalFS LocalFSConfiguration
+
+	// Import holds the settings of the `import` source.
+	Import ImportConfiguration
+
+	// PassiveDNS holds the settings of the passive DNS sources.
+	PassiveDNS PassiveDNSConfiguration
+
+	// HostSearch holds the settings of the internet scan search engine sources.
+	HostSearch HostSearchConfiguration
+
+	// Whois holds the settings of the `whois` stage.
+	Whois WhoisConfiguration
+
+	// Resolver resolves names for sources that query DNS.
+	Resolver *resolver.Resolver
+
+	// Bruteforce holds the settings of the `bruteforce` source.
+	Bruteforce BruteforceConfiguration
+
+	// Permutation holds the settings of the `permutation` stage.
+	Permutation PermutationConfiguration
+
+	// ZoneWalk holds the settings of the `zonewalk` source.
+	ZoneWalk ZoneWalkConfiguration
+
+	// DNSRecords holds the settings of the `dnsrecords` stage.
+	DNSRecords DNSRecordsConfiguration
+
+	// TLSGrab holds the settings of the `tlsgrab` stage.
+	TLSGrab TLSGrabConfiguration
+
+	// Crawl holds the settings of the `crawl` stage.
+	Crawl CrawlConfiguration
+
+	// Takeover holds the settings of the `takeover` stage.
+	Takeover TakeoverConfiguration
+}
+
+// CTLogConfiguration holds the settings of the `ctlog` source, which reads
+// Certificate Transparency logs directly over the RFC 6962 API.
+type CTLogConfiguration struct {
+	// Logs are the base URLs of the CT logs to read, e.g. "https://ct.googleapis.com/logs/us1/argon2026h2/".
+	Logs []string `yaml:"logs" mapstructure:"logs"`
+	// MaxEntries caps the number of new entries read from each log per run.
+	MaxEntries int `yaml:"max_entries" mapstructure:"max_entries"`
+	// CacheDirectory keeps, per log, the range of entries already processed and
+	// the names found in it, so re-scans only read entries added since.
+	CacheDirectory string `yaml:"cache_directory" mapstructure:"cache_directory"`
+	// MaxCachedNames caps the number of distinct names cached per log, the
+	// oldest are dropped first.
+	MaxCachedNames int `yaml:"max_cached_names" mapstructure:"max_cached_names"`
+}
+
+// GitLabConfiguration holds the settings of the `gitlab` source, which
+// searches code blobs through the GitLab search API.
+type GitLabConfiguration struct {
+	// BaseURL is the URL of the GitLab instance, e.g. "https://gitlab.example.com"
+	// for a self-hosted one. If not set, "https://gitlab.com" is used.
+	BaseURL string `yaml:"base_url" mapstructure:"base_url"`
+}
+
+// LocalFSConfiguration holds the settings of the `localfs` source, which
+// searches local files, directories and git repositories.
+type LocalFSConfiguration struct {
+	// Paths are the files and directories searched, directories recursively.
+	Paths []string `yaml:"paths" mapstructure:"paths"`
+	// History, if set, also searches every blob in the history of the git
+	// repositories found under Paths, on all branches and tags.
+	History bool `yaml:"history" mapstructure:"history"`
+	// MaxSize caps the size in bytes of the files searched, larger ones are skipped.
+	MaxSize int64 `yaml:"max_size" mapstructure:"max_size"`
+}
+
+// ImportConfiguration holds the settings of the `import` source, which reads
+// the hosts found in previous runs of other tools.
+type ImportConfiguration struct {
+	// Files are the files imported: HAR files, Burp Suite XML exports, Nmap
+	// XML reports, Amass and subfinder JSON lines, massdns output or plain
+	// host lists. Their format is detected from their content.
+	Files []string `yaml:"files" mapstructure:"files"`
+}
+
+// PassiveDNSConfiguration holds the settings of the passive DNS sources:
+// `circl`, `dnsdb` and `mnemonic`.
+type PassiveDNSConfiguration struct {
+	// Since, if set, fences queries to records last seen within that
+	// duration, e.g. "8760h" for the last year.
+	Since string `yaml:"since" mapstructure:"since"`
+	// MaxPages caps the number of result pages requested per source.
+	MaxPages int `yaml:"max_pages" mapstructure:"max_pages"`
+}
+
+// SinceTime returns the time records must have been last seen after, or the
+// zero time if queries are not fenced.
+func (cfg PassiveDNSConfiguration) SinceTime() (since time.Time, err error) {
+	if cfg.Since == "" {
+		return
+	}
+
+	var duration time.Duration
+
+	duration, err = time.ParseDuration(cfg.Since)
+	if err != nil {
+		return
+	}
+
+	since = time.Now().Add(-duration)
+
+	return
+}
+
+// HostSearchConfiguration holds the settings of the sources searching
+// internet scan datasets: `fofa`, `hunter`, `netlas` and `zoomeye`.
+type HostSearchConfiguration struct {
+	// MaxPages caps the number of result pages requested per source.
+	MaxPages int `yaml:"max_pages" mapstructure:"max_pages"`
+}
+
+// WhoisConfiguration holds the settings of the `whois` stage, which finds
+// root domains registered by the target's owner.
+type WhoisConfiguration struct {
+	// MaxDomains caps the number of related root domains reported per domain.
+	MaxDomains int `yaml:"max_domains" mapstructure:"max_domains"`
+}
+
+// BruteforceConfiguration holds the settings of the `bruteforce` source, which
+// resolves the names built from a wordlist and the target domain.
+type BruteforceConfiguration struct {
+	// Wordlist is the path of the wordlist file, one word per line. If not
+	// set, a small built-in wordlist of common subdomain labels is used.
+	Wordlist string `yaml:"wordlist" mapstructure:"wordlist"`
+	// Concurrency is the number of names resolved at a time.
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+}
+
+// PermutationConfiguration holds the settings of the `permutation` stage, which
+// resolves variations of the subdomains found by the sources.
+type PermutationConfiguration struct {
+	// Words are inserted into, joined with and swapped with the labels of found
+	// subdomains, on top of the tokens learnt from those labels.
+	Words []string `yaml:"words" mapstructure:"words"`
+	// MaxTokens caps the number of tokens learnt from found subdomains, the
+	// most frequent ones are kept.
+	MaxTokens int `yaml:"max_tokens" mapstructure:"max_tokens"`
+	// MaxCandidates caps the number of candidates resolved per domain.
+	MaxCandidates int `yaml:"max_candidates" mapstructure:"max_candidates"`
+	// Concurrency is the number of candidates resolved at a time.
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+}
+
+// ZoneWalkConfiguration holds the settings of the `zonewalk` source, which
+// tries zone transfers (AXFR) and walks the DNSSEC chain of the target's zone.
+type ZoneWalkConfiguration struct {
+	// Nameservers, as `ip` or `ip:port`, are queried instead of the target's
+	// authoritative nameservers, looked up otherwise.
+	Nameservers []string `yaml:"nameservers" mapstructure:"nameservers"`
+	// MaxNames caps the number of names listed by walking an NSEC chain.
+	MaxNames int `yaml:"max_names" mapstructure:"max_names"`
+	// NSEC3Queries caps the number of queries made to collect NSEC3 hashes.
+	NSEC3Queries int `yaml:"nsec3_queries" mapstructure:"nsec3_queries"`
+	// Wordlist is the path of the wordlist NSEC3 hashes are cracked against.
+	// If not set, the built-in wordlist is used.
+	Wordlist string `yaml:"wordlist" mapstructure:"wordlist"`
+	// HashesFile, if set, is the file NSEC3 hashes are appended to, in the
+	// hashcat (mode 8300) format, for cracking with larger wordlists.
+	HashesFile string `yaml:"hashes_file" mapstructure:"hashes_file"`
+}
+
+// DNSRecordsConfiguration holds the settings of the `dnsrecords` stage, which
+// mines the DNS records of the target domain and of the subdomains found.
+type DNSRecordsConfiguration struct {
+	// SRVServices are the `_service._proto` labels whose SRV records are
+	// queried for the target domain, e.g. "_sip._tcp".
+	SRVServices []string `yaml:"srv_services" mapstructure:"srv_services"`
+	// MaxNames caps the number of found subdomains whose records are queried.
+	MaxNames int `yaml:"max_names" mapstructure:"max_names"`
+	// Concurrency is the number of names queried at a time.
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+}
+
+// TLSGrabConfiguration holds the settings of the `tlsgrab` stage, which
+// harvests names from the certificates served by the subdomains found.
+type TLSGrabConfiguration struct {
+	// Ports are the ports TLS handshakes are attempted on.
+	Ports []int `yaml:"ports" mapstructure:"ports"`
+	// MaxHosts caps the number of found subdomains connected to.
+	MaxHosts int `yaml:"max_hosts" mapstructure:"max_hosts"`
+	// Concurrency is the number of handshakes made at a time.
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+	// Timeout is the per-handshake timeout, e.g. "5s".
+	Timeout string `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// CrawlConfiguration holds the settings of the `crawl` stage, which fetches
+// a bounded set of pages of the subdomains found and searches them for more.
+type CrawlConfiguration struct {
+	// MaxHosts caps the number of found subdomains crawled.
+	MaxHosts int `yaml:"max_hosts" mapstructure:"max_hosts"`
+	// MaxDepth caps the number of links followed from the root page of a host.
+	MaxDepth int `yaml:"max_depth" mapstructure:"max_depth"`
+	// MaxPages caps the number of pages fetched per host.
+	MaxPages int `yaml:"max_pages" mapstructure:"max_pages"`
+	// MaxSize caps the number of bytes read from each response body.
+	MaxSize int64 `yaml:"max_size" mapstructure:"max_size"`
+	// Concurrency is the number of hosts crawled at a time.
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+	// Timeout is the per-request timeout, e.g. "10s".
+	Timeout string `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// TakeoverConfiguration holds the settings of the `takeover` stage, which
+// resolves the CNAMEs of the subdomains found and matches them against
+// fingerprints of dangling services.
+type TakeoverConfiguration struct {
+	// Fingerprints is the path of a YAML file of fingerprints used instead of
+	// the built-in ones.
+	Fingerprints string `yaml:"fingerprints" mapstructure:"fingerprints"`
+	// MaxHosts caps the number of found subdomains checked.
+	MaxHosts int `yaml:"max_hosts" mapstructure:"max_hosts"`
+	// Concurrency is the number of subdomains checked at a time.
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+	// Timeout is the per-request timeout, e.g. "10s".
+	Timeout string `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// ProbeConfiguration holds the settings of the probing of the web servers of
+// the subdomains found, over HTTP and HTTPS.
+type ProbeConfiguration struct {
+	// Enabled, if true, probes the subdomains found once enumeration is done.
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// HTTPPorts are the ports probed over HTTP.
+	HTTPPorts []int `yaml:"http_ports" mapstructure:"http_ports"`
+	// HTTPSPorts are the ports probed over HTTPS.
+	HTTPSPorts []int `yaml:"https_ports" mapstructure:"https_ports"`
+	// MaxRedirects caps the number of redirects followed per probe, none are
+	// followed if 0. It is a pointer so that 0 can be told from unset.
+	MaxRedirects *int `yaml:"max_redirects" mapstructure:"max_redirects"`
+	// MaxSize caps the number of bytes read from each response body.
+	MaxSize int64 `yaml:"max_size" mapstructure:"max_size"`
+	// Concurrency is the number of probes made at a time.
+	Concurrency int `yaml:"concurrency" mapstructure:"concurrency"`
+	// Timeout is the per-probe timeout, redirects included, e.g. "10s".
+	Timeout string `yaml:"timeout" mapstructure:"timeout"`
+	// Cluster, if true, groups the responses probed into clusters of similar
+	// ones, by body and headers.
+	Cluster bool `yaml:"cluster" mapstructure:"cluster"`
+	// MaxDistance is the number of bits, out of 64, the SimHashes of the
+	// bodies of responses in the same cluster differ in at most. It is a
+	// pointer so that 0, only grouping bodies hashing the same, can be told
+	// from unset.
+	MaxDistance *int `yaml:"max_distance" mapstructure:"max_distance"`
+}
+
+// Cursors persists pagination cursors, allowing an interrupted paginated
+// source to continue from the last page it completed instead of the first.
+type Cursors interface {
+	Cursor(domain, source string) (cursor string)
+	SetCursor(domain, source, cursor string)
+}
+
+// Cursor returns the pagination cursor saved for source and domain, if any.
+func (cfg *Configuration) Cursor(domain, source string) (cursor string) {
+	if cfg.Cursors == nil {
+		return
+	}
+
+	cursor = cfg.Cursors.Cursor(domain, source)
+
+	return
+}
+
+// SetCursor saves the pagination cursor from which source should continue for domain.
+func (cfg *Configuration) SetCursor(domain, source, cursor string) {
+	if cfg.Cursors == nil {
+		return
+	}
+
+	cfg.Cursors.SetCursor(domain, source, cursor)
 }
 
 // Keys holds API keys for different data sources, with each source having a set of API keys.
@@ -23,14 +316,23 @@ type Keys struct {
 	Censys         SourceKeys `yaml:"censys"`
 	Certspotter    SourceKeys `yaml:"certspotter"`
 	Chaos          SourceKeys `yaml:"chaos"`
+	CIRCL          SourceKeys `yaml:"circl"`
+	DNSDB          SourceKeys `yaml:"dnsdb"`
+	FOFA           SourceKeys `yaml:"fofa"`
 	Fullhunt       SourceKeys `yaml:"fullhunt"`
 	GitHub         SourceKeys `yaml:"github"`
+	GitLab         SourceKeys `yaml:"gitlab"`
+	Hunter         SourceKeys `yaml:"hunter"`
 	Intelx         SourceKeys `yaml:"intelx"`
 	LeakIX         SourceKeys `yaml:"leakix"`
+	Mnemonic       SourceKeys `yaml:"mnemonic"`
+	Netlas         SourceKeys `yaml:"netlas"`
 	SecurityTrails SourceKeys `yaml:"securitytrails"`
 	Shodan         SourceKeys `yaml:"shodan"`
 	URLScan        SourceKeys `yaml:"urlscan"`
 	VirusTotal     SourceKeys `yaml:"virustotal"`
+	WhoisXML       SourceKeys `yaml:"whoisxml"`
+	ZoomEye        SourceKeys `yaml:"zoomeye"`
 }
 
 // SourceKeys is a slice of strings representing API keys. Multiple API keys
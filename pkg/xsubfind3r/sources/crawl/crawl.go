@@ -0,0 +1,342 @@
+package crawl
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/internal/configuration"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type Stage struct{}
+
+// page is a URL queued for fetching, at depth links from the root page.
+type page struct {
+	url   *url.URL
+	depth int
+}
+
+const (
+	defaultMaxHosts    = 500
+	defaultMaxDepth    = 2
+	defaultMaxPages    = 20
+	defaultMaxSize     = 2 << 20
+	defaultConcurrency = 10
+	defaultTimeout     = 10 * time.Second
+)
+
+var (
+	// linkRegex matches the targets of `src` and `href` attributes.
+	linkRegex = regexp.MustCompile(`(?i)(?:src|href)\s*=\s*["']([^"'#]+)`)
+	// locRegex matches the URLs of a sitemap.
+	locRegex = regexp.MustCompile(`(?i)<loc>\s*([^<\s]+)\s*</loc>`)
+	// sitemapRegex matches the `Sitemap:` lines of a robots.txt.
+	sitemapRegex = regexp.MustCompile(`(?im)^\s*sitemap:\s*(\S+)`)
+)
+
+func (stage *Stage) Run(ctx context.Context, cfg *sources.Configuration, domain string, subdomains []string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		c := &crawler{
+			cfg:      cfg,
+			stage:    stage,
+			maxDepth: cfg.Crawl.MaxDepth,
+			maxPages: cfg.Crawl.MaxPages,
+			maxSize:  cfg.Crawl.MaxSize,
+			results:  results,
+		}
+
+		if c.maxDepth < 1 {
+			c.maxDepth = defaultMaxDepth
+		}
+
+		if c.maxPages < 1 {
+			c.maxPages = defaultMaxPages
+		}
+
+		if c.maxSize < 1 {
+			c.maxSize = defaultMaxSize
+		}
+
+		maxHosts := cfg.Crawl.MaxHosts
+
+		if maxHosts < 1 {
+			maxHosts = defaultMaxHosts
+		}
+
+		concurrency := cfg.Crawl.Concurrency
+
+		if concurrency < 1 {
+			concurrency = defaultConcurrency
+		}
+
+		timeout := defaultTimeout
+
+		if cfg.Crawl.Timeout != "" {
+			var err error
+
+			timeout, err = time.ParseDuration(cfg.Crawl.Timeout)
+			if err != nil {
+				stage.error(err, results)
+
+				return
+			}
+		}
+
+		c.client = &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true, //nolint:gosec // Hosts are crawled for names, not trusted.
+					MinVersion:         tls.VersionTLS10,
+				},
+			},
+			// Redirects are followed by the crawler, their Location headers are searched too.
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		hosts := []string{domain}
+
+		for _, subdomain := range subdomains {
+			if len(hosts) >= maxHosts {
+				break
+			}
+
+			if subdomain != domain {
+				hosts = append(hosts, subdomain)
+			}
+		}
+
+		queue := make(chan string)
+
+		wg := &sync.WaitGroup{}
+
+		for range concurrency {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for host := range queue {
+					c.host(ctx, host)
+				}
+			}()
+		}
+
+	feed:
+		for _, host := range hosts {
+			select {
+			case <-ctx.Done():
+				break feed
+			case queue <- host:
+			}
+		}
+
+		close(queue)
+
+		wg.Wait()
+	}()
+
+	return results
+}
+
+func (stage *Stage) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: stage.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (stage *Stage) Name() string {
+	return sources.CRAWL
+}
+
+type crawler struct {
+	cfg    *sources.Configuration
+	stage  *Stage
+	client *http.Client
+
+	maxDepth int
+	maxPages int
+	maxSize  int64
+
+	// seen holds the names already reported, each is reported with the first URL it was found in.
+	seen sync.Map
+
+	results chan sources.Result
+}
+
+// host crawls host, over HTTPS or, if it does not answer it, over HTTP.
+func (c *crawler) host(ctx context.Context, host string) {
+	for _, scheme := range []string{"https", "http"} {
+		root := &url.URL{Scheme: scheme, Host: host, Path: "/"}
+
+		if c.crawl(ctx, root) {
+			return
+		}
+	}
+}
+
+// crawl fetches root, its robots.txt and sitemap.xml, then the pages and
+// scripts they link to on the same host, breadth first, within the depth and
+// page limits. It reports whether root could be fetched at all.
+func (c *crawler) crawl(ctx context.Context, root *url.URL) (reachable bool) {
+	queue := []page{
+		{url: root},
+		{url: root.ResolveReference(&url.URL{Path: "/robots.txt"})},
+		{url: root.ResolveReference(&url.URL{Path: "/sitemap.xml"})},
+	}
+
+	visited := map[string]struct{}{}
+
+	for fetched := 0; len(queue) > 0 && fetched < c.maxPages; {
+		if ctx.Err() != nil {
+			return
+		}
+
+		current := queue[0]
+
+		queue = queue[1:]
+
+		if _, ok := visited[current.url.String()]; ok {
+			continue
+		}
+
+		visited[current.url.String()] = struct{}{}
+
+		fetched++
+
+		links, err := c.fetch(ctx, current.url)
+		if err != nil {
+			// An unreachable root means the host does not serve this scheme.
+			if current.url == root {
+				return
+			}
+
+			continue
+		}
+
+		reachable = true
+
+		if current.depth >= c.maxDepth {
+			continue
+		}
+
+		for _, link := range links {
+			if link.Host != root.Host || (link.Scheme != "http" && link.Scheme != "https") {
+				continue
+			}
+
+			link.Fragment = ""
+
+			queue = append(queue, page{url: link, depth: current.depth + 1})
+		}
+	}
+
+	return
+}
+
+// fetch fetches target, reports the names found in its headers and body, and
+// returns the URLs it links to: its redirect, and the links, scripts,
+// sitemap entries and sitemaps it references.
+func (c *crawler) fetch(ctx context.Context, target *url.URL) (links []*url.URL, err error) {
+	var req *http.Request
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, target.String(), http.NoBody)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("User-Agent", fmt.Sprintf("%s v%s (https://github.com/hueristiq/%s)", configuration.NAME, configuration.VERSION, configuration.NAME))
+
+	var res *http.Response
+
+	res, err = c.client.Do(req)
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	var body []byte
+
+	body, err = io.ReadAll(io.LimitReader(res.Body, c.maxSize))
+	if err != nil {
+		return
+	}
+
+	// Headers such as Content-Security-Policy, Access-Control-Allow-Origin,
+	// Location or Link often name other hosts.
+	headers := &strings.Builder{}
+
+	for key, values := range res.Header {
+		fmt.Fprintf(headers, "%s: %s\n", key, strings.Join(values, ", "))
+	}
+
+	c.extract(headers.String(), target)
+	c.extract(string(body), target)
+
+	var references []string
+
+	if location := res.Header.Get("Location"); location != "" {
+		references = append(references, location)
+	}
+
+	for _, regex := range []*regexp.Regexp{linkRegex, locRegex, sitemapRegex} {
+		for _, match := range regex.FindAllStringSubmatch(string(body), -1) {
+			references = append(references, match[1])
+		}
+	}
+
+	for _, reference := range references {
+		var link *url.URL
+
+		link, err = target.Parse(strings.TrimSpace(reference))
+		if err != nil {
+			continue
+		}
+
+		links = append(links, link)
+	}
+
+	err = nil
+
+	return
+}
+
+// extract reports the names in text, with the URL of text as evidence.
+func (c *crawler) extract(text string, source *url.URL) {
+	for _, name := range c.cfg.Extractor.FindAllString(text, -1) {
+		name = strings.ToLower(name)
+
+		if _, loaded := c.seen.LoadOrStore(name, struct{}{}); loaded {
+			continue
+		}
+
+		result := sources.Result{
+			Type:     sources.ResultSubdomain,
+			Source:   c.stage.Name(),
+			Value:    name,
+			Evidence: source.String(),
+		}
+
+		c.results <- result
+	}
+}
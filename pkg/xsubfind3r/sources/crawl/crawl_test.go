@@ -0,0 +1,137 @@
+package crawl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// site serves a chain of pages, `/` linking to `/1`, `/1` to `/2` and so on,
+// each naming `page<N>.example.com`, and `/large`, naming `head.example.com`
+// at the start of its body and `tail.example.com` past 1 KiB.
+func site(t *testing.T) (server *httptest.Server, requested func() []string) {
+	t.Helper()
+
+	mutex := sync.Mutex{}
+
+	var paths []string
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		paths = append(paths, r.URL.Path)
+		mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/robots.txt", "/sitemap.xml":
+			http.NotFound(w, r)
+		case "/large":
+			w.Write([]byte("head.example.com " + strings.Repeat(" ", 1024) + " tail.example.com"))
+		default:
+			number := strings.TrimPrefix(r.URL.Path, "/")
+
+			if number == "" {
+				number = "0"
+			}
+
+			next := string(rune(number[0] + 1))
+
+			w.Write([]byte(`<p>page` + number + `.example.com</p><a href="/` + next + `">next</a>`))
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	requested = func() []string {
+		mutex.Lock()
+
+		defer mutex.Unlock()
+
+		return append([]string{}, paths...)
+	}
+
+	return
+}
+
+func crawl(t *testing.T, root string, maxDepth, maxPages int, maxSize int64) (names []string) {
+	t.Helper()
+
+	target, err := url.Parse(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := make(chan sources.Result)
+
+	c := &crawler{
+		cfg: &sources.Configuration{
+			Extractor: regexp.MustCompile(`[a-z0-9-]+\.example\.com`),
+		},
+		stage:    &Stage{},
+		client:   &http.Client{},
+		maxDepth: maxDepth,
+		maxPages: maxPages,
+		maxSize:  maxSize,
+		results:  results,
+	}
+
+	go func() {
+		defer close(results)
+
+		c.crawl(context.Background(), target)
+	}()
+
+	for result := range results {
+		names = append(names, result.Value)
+	}
+
+	slices.Sort(names)
+
+	return
+}
+
+func TestCrawlMaxDepth(t *testing.T) {
+	server, requested := site(t)
+
+	got := crawl(t, server.URL+"/", 2, 20, defaultMaxSize)
+
+	if want := []string{"page0.example.com", "page1.example.com", "page2.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+
+	if slices.Contains(requested(), "/3") {
+		t.Errorf("requested = %v, want no page past the maximum depth", requested())
+	}
+}
+
+func TestCrawlMaxPages(t *testing.T) {
+	server, requested := site(t)
+
+	// The root page, then robots.txt.
+	got := crawl(t, server.URL+"/", 5, 2, defaultMaxSize)
+
+	if want := []string{"page0.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+
+	if paths := requested(); len(paths) != 2 {
+		t.Errorf("requested = %v, want 2 pages", paths)
+	}
+}
+
+func TestCrawlMaxSize(t *testing.T) {
+	server, _ := site(t)
+
+	got := crawl(t, server.URL+"/large", 0, 1, 1024)
+
+	if want := []string{"head.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+}
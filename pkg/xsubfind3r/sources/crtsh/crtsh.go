@@ -1,6 +1,7 @@
 package crtsh
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -16,7 +17,7 @@ type getNameValuesResponse []struct {
 
 type Source struct{}
 
-func (source *Source) Run(_ *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, _ *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -24,7 +25,7 @@ func (source *Source) Run(_ *sources.Configuration, domain string) <-chan source
 
 		getNameValuesReqURL := fmt.Sprintf("https://crt.sh/?q=%%25.%s&output=json", domain)
 
-		getNameValuesRes, err := httpclient.SimpleGet(getNameValuesReqURL)
+		getNameValuesRes, err := httpclient.SimpleGet(ctx, getNameValuesReqURL)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
@@ -0,0 +1,499 @@
+package ctlog
+
+import (
+	"bufio"
+	"context"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type getSTHResponse struct {
+	TreeSize uint64 `json:"tree_size"`
+}
+
+type getEntriesResponse struct {
+	Entries []struct {
+		LeafInput []byte `json:"leaf_input"`
+		ExtraData []byte `json:"extra_data"`
+	} `json:"entries"`
+}
+
+// state tells how far a log was processed: entries before End were read, but
+// for those in Gaps, skipped when more entries were added between two runs
+// than a run reads.
+type state struct {
+	End  uint64 `json:"end"`
+	Gaps []span `json:"gaps,omitempty"`
+}
+
+// span is the range of entries from Start, included, to End, excluded.
+type span struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+type Source struct{}
+
+const (
+	// batchSize is the number of entries requested per get-entries call,
+	// logs may return fewer, in which case the next call continues after them.
+	batchSize = 256
+	// defaultMaxEntries is used when no cap is configured.
+	defaultMaxEntries = 10000
+	// defaultMaxCachedNames is used when no cap on the cache is configured.
+	defaultMaxCachedNames = 1000000
+
+	// Values of the MerkleTreeLeaf and LogEntryType enums of RFC 6962.
+	leafTypeTimestampedEntry = 0
+	entryTypeX509            = 0
+	entryTypePrecert         = 1
+)
+
+var (
+	unsafeCharsRegex = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+	errUnsupportedLeaf = errors.New("unsupported leaf")
+	errTruncatedLeaf   = errors.New("truncated leaf")
+)
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		maxEntries := uint64(cfg.CTLog.MaxEntries)
+
+		if maxEntries == 0 {
+			maxEntries = defaultMaxEntries
+		}
+
+		maxCachedNames := cfg.CTLog.MaxCachedNames
+
+		if maxCachedNames <= 0 {
+			maxCachedNames = defaultMaxCachedNames
+		}
+
+		for _, logURL := range cfg.CTLog.Logs {
+			if ctx.Err() != nil {
+				return
+			}
+
+			source.read(ctx, strings.TrimSuffix(logURL, "/"), cfg.CTLog.CacheDirectory, maxEntries, maxCachedNames, domain, results)
+		}
+	}()
+
+	return results
+}
+
+// read emits the names under domain cached for a log, then reads up to
+// maxEntries entries added to the log since the last run, or skipped by it.
+func (source *Source) read(ctx context.Context, logURL, cacheDirectory string, maxEntries uint64, maxCachedNames int, domain string, results chan sources.Result) {
+	var err error
+
+	var cache *logCache
+
+	if cacheDirectory != "" {
+		cache, err = openLogCache(filepath.Join(cacheDirectory, unsafeCharsRegex.ReplaceAllString(logURL, "_")), maxCachedNames)
+		if err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		defer func() {
+			if err := cache.close(); err != nil {
+				source.error(err, results)
+			}
+		}()
+
+		for _, name := range cache.search(domain) {
+			source.subdomain(name, results)
+		}
+	}
+
+	getSTHReqURL := logURL + "/ct/v1/get-sth"
+
+	var getSTHRes *http.Response
+
+	getSTHRes, err = httpclient.SimpleGet(ctx, getSTHReqURL)
+	if err != nil {
+		source.error(err, results)
+
+		httpclient.DiscardResponse(getSTHRes)
+
+		return
+	}
+
+	var getSTHResData getSTHResponse
+
+	if err = json.NewDecoder(getSTHRes.Body).Decode(&getSTHResData); err != nil {
+		source.error(err, results)
+
+		getSTHRes.Body.Close()
+
+		return
+	}
+
+	getSTHRes.Body.Close()
+
+	treeSize := getSTHResData.TreeSize
+
+	processed := state{}
+
+	if cache != nil {
+		processed = cache.state
+	}
+
+	// A log never read before is read from its tail, the most recent entries.
+	// Entries added since the last run are read newest first, those left over
+	// are backfilled by later runs, once they have caught up with the tail.
+	switch {
+	case processed.End == 0:
+		processed = state{End: treeSize, Gaps: []span{{Start: treeSize - min(treeSize, maxEntries), End: treeSize}}}
+	case treeSize > processed.End:
+		processed.Gaps = append(processed.Gaps, span{Start: processed.End, End: treeSize})
+		processed.End = treeSize
+	}
+
+	for budget := maxEntries; budget > 0 && len(processed.Gaps) > 0; {
+		gap := processed.Gaps[len(processed.Gaps)-1]
+
+		if gap.Start >= gap.End {
+			processed.Gaps = processed.Gaps[:len(processed.Gaps)-1]
+
+			continue
+		}
+
+		start := max(gap.Start, gap.End-min(gap.End, budget))
+
+		getEntriesReqURL := fmt.Sprintf("%s/ct/v1/get-entries?start=%d&end=%d", logURL, start, min(start+batchSize, gap.End)-1)
+
+		var getEntriesRes *http.Response
+
+		getEntriesRes, err = httpclient.SimpleGet(ctx, getEntriesReqURL)
+		if err != nil {
+			source.error(err, results)
+
+			httpclient.DiscardResponse(getEntriesRes)
+
+			return
+		}
+
+		var getEntriesResData getEntriesResponse
+
+		if err = json.NewDecoder(getEntriesRes.Body).Decode(&getEntriesResData); err != nil {
+			source.error(err, results)
+
+			getEntriesRes.Body.Close()
+
+			return
+		}
+
+		getEntriesRes.Body.Close()
+
+		if len(getEntriesResData.Entries) == 0 {
+			return
+		}
+
+		var names []string
+
+		for _, entry := range getEntriesResData.Entries {
+			var certificate *x509.Certificate
+
+			certificate, err = parseEntry(entry.LeafInput, entry.ExtraData)
+			if err != nil {
+				continue
+			}
+
+			names = append(names, sources.CertificateNames(certificate)...)
+		}
+
+		for _, name := range names {
+			if name == domain || strings.HasSuffix(name, "."+domain) {
+				source.subdomain(name, results)
+			}
+		}
+
+		read := min(uint64(len(getEntriesResData.Entries)), gap.End-start)
+
+		budget -= min(budget, read)
+
+		// The gap is split around the entries read, the part after them is
+		// read next, the part before them by a later run.
+		processed.Gaps = processed.Gaps[:len(processed.Gaps)-1]
+
+		if gap.Start < start {
+			processed.Gaps = append(processed.Gaps, span{Start: gap.Start, End: start})
+		}
+
+		if start+read < gap.End {
+			processed.Gaps = append(processed.Gaps, span{Start: start + read, End: gap.End})
+		}
+
+		if cache != nil {
+			if err = cache.add(names, processed); err != nil {
+				source.error(err, results)
+
+				return
+			}
+		}
+	}
+}
+
+func (source *Source) subdomain(name string, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultSubdomain,
+		Source: source.Name(),
+		Value:  name,
+	}
+
+	results <- result
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.CTLOG
+}
+
+// parseEntry parses the certificate of a log entry, as returned by get-entries.
+// For X.509 entries the certificate is in the leaf itself. For precertificate
+// entries the leaf only holds the TBSCertificate, so the full precertificate is
+// read from the extra data (the PrecertChainEntry) instead.
+func parseEntry(leafInput, extraData []byte) (certificate *x509.Certificate, err error) {
+	// MerkleTreeLeaf: version (1), leaf_type (1), timestamp (8), entry_type (2).
+	if len(leafInput) < 12 {
+		err = errTruncatedLeaf
+
+		return
+	}
+
+	if leafInput[1] != leafTypeTimestampedEntry {
+		err = errUnsupportedLeaf
+
+		return
+	}
+
+	var der []byte
+
+	switch binary.BigEndian.Uint16(leafInput[10:12]) {
+	case entryTypeX509:
+		der, err = readOpaque24(leafInput[12:])
+	case entryTypePrecert:
+		der, err = readOpaque24(extraData)
+	default:
+		err = errUnsupportedLeaf
+	}
+
+	if err != nil {
+		return
+	}
+
+	// Precertificates carry a critical poison extension, which the parser
+	// records as unhandled rather than failing on.
+	certificate, err = x509.ParseCertificate(der)
+
+	return
+}
+
+// readOpaque24 reads a TLS opaque vector with a 24-bit length prefix.
+func readOpaque24(data []byte) (value []byte, err error) {
+	if len(data) < 3 {
+		err = errTruncatedLeaf
+
+		return
+	}
+
+	length := int(data[0])<<16 | int(data[1])<<8 | int(data[2])
+
+	if len(data) < 3+length {
+		err = errTruncatedLeaf
+
+		return
+	}
+
+	value = data[3 : 3+length]
+
+	return
+}
+
+// logCache is the on-disk cache of a log: `state.json` tells how far the log
+// was processed and `names.txt` holds the distinct names found, oldest first.
+// Names are held in memory while the cache is open, and the file is compacted,
+// down to the last maxNames names, when it is closed.
+type logCache struct {
+	directory string
+	state     state
+
+	file     *os.File
+	lines    int
+	names    []string
+	index    map[string]struct{}
+	maxNames int
+}
+
+// search returns the cached names that are domain or under it.
+func (cache *logCache) search(domain string) (found []string) {
+	for _, name := range cache.names {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			found = append(found, name)
+		}
+	}
+
+	return
+}
+
+// add appends the names not cached yet, then records processed, so that a
+// crash between the two at worst re-reads a batch already cached.
+func (cache *logCache) add(names []string, processed state) (err error) {
+	writer := bufio.NewWriter(cache.file)
+
+	for _, name := range names {
+		if _, ok := cache.index[name]; ok {
+			continue
+		}
+
+		cache.index[name] = struct{}{}
+		cache.names = append(cache.names, name)
+		cache.lines++
+
+		fmt.Fprintln(writer, name)
+	}
+
+	if err = writer.Flush(); err != nil {
+		return
+	}
+
+	cache.state = processed
+
+	var raw []byte
+
+	raw, err = json.Marshal(processed)
+	if err != nil {
+		return
+	}
+
+	err = replaceFile(filepath.Join(cache.directory, "state.json"), raw)
+
+	return
+}
+
+// close closes the names file, compacting it if it holds duplicates, e.g.
+// from older versions, or more than maxNames names.
+func (cache *logCache) close() (err error) {
+	if err = cache.file.Close(); err != nil {
+		return
+	}
+
+	if cache.lines == len(cache.names) && len(cache.names) <= cache.maxNames {
+		return
+	}
+
+	names := cache.names[max(0, len(cache.names)-cache.maxNames):]
+
+	var builder strings.Builder
+
+	for _, name := range names {
+		builder.WriteString(name)
+		builder.WriteByte('\n')
+	}
+
+	err = replaceFile(filepath.Join(cache.directory, "names.txt"), []byte(builder.String()))
+
+	return
+}
+
+// replaceFile atomically replaces the file at path with data.
+func replaceFile(path string, data []byte) (err error) {
+	if err = os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return
+	}
+
+	err = os.Rename(path+".tmp", path)
+
+	return
+}
+
+func openLogCache(directory string, maxNames int) (cache *logCache, err error) {
+	cache = &logCache{
+		directory: directory,
+		index:     map[string]struct{}{},
+		maxNames:  maxNames,
+	}
+
+	if err = os.MkdirAll(directory, os.ModePerm); err != nil {
+		return
+	}
+
+	var raw []byte
+
+	raw, err = os.ReadFile(filepath.Join(directory, "state.json"))
+
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(raw, &cache.state); err != nil {
+			return
+		}
+	case errors.Is(err, os.ErrNotExist):
+		err = nil
+	default:
+		return
+	}
+
+	cache.file, err = os.OpenFile(filepath.Join(directory, "names.txt"), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(cache.file)
+
+	for scanner.Scan() {
+		name := scanner.Text()
+
+		cache.lines++
+
+		if _, ok := cache.index[name]; ok {
+			continue
+		}
+
+		cache.index[name] = struct{}{}
+		cache.names = append(cache.names, name)
+	}
+
+	if err = scanner.Err(); err != nil {
+		cache.file.Close()
+
+		return
+	}
+
+	// Only the names kept once compacted are searched.
+	if excess := len(cache.names) - maxNames; excess > 0 {
+		for _, name := range cache.names[:excess] {
+			delete(cache.index, name)
+		}
+
+		cache.names = cache.names[excess:]
+	}
+
+	return
+}
@@ -0,0 +1,261 @@
+package ctlog
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// stubLog serves the get-sth and get-entries endpoints of a log holding a
+// certificate per entry, for the names given.
+type stubLog struct {
+	*httptest.Server
+
+	mutex  sync.Mutex
+	leaves [][]byte
+
+	entriesRequests atomic.Int32
+}
+
+func newStubLog(t *testing.T, names ...[]string) (log *stubLog) {
+	t.Helper()
+
+	log = &stubLog{}
+
+	log.add(t, names...)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/ct/v1/get-sth", func(w http.ResponseWriter, _ *http.Request) {
+		log.mutex.Lock()
+		defer log.mutex.Unlock()
+
+		json.NewEncoder(w).Encode(getSTHResponse{TreeSize: uint64(len(log.leaves))})
+	})
+
+	mux.HandleFunc("/ct/v1/get-entries", func(w http.ResponseWriter, r *http.Request) {
+		log.entriesRequests.Add(1)
+
+		log.mutex.Lock()
+		defer log.mutex.Unlock()
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		end, _ := strconv.Atoi(r.URL.Query().Get("end"))
+
+		var entries getEntriesResponse
+
+		for index := start; index <= end && index < len(log.leaves); index++ {
+			entries.Entries = append(entries.Entries, struct {
+				LeafInput []byte `json:"leaf_input"`
+				ExtraData []byte `json:"extra_data"`
+			}{LeafInput: log.leaves[index]})
+		}
+
+		json.NewEncoder(w).Encode(entries)
+	})
+
+	log.Server = httptest.NewServer(mux)
+
+	t.Cleanup(log.Close)
+
+	return
+}
+
+// add appends an entry per certificate of names to the log.
+func (log *stubLog) add(t *testing.T, names ...[]string) {
+	t.Helper()
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	for _, dnsNames := range names {
+		log.leaves = append(log.leaves, leaf(t, dnsNames))
+	}
+}
+
+// leaf returns the MerkleTreeLeaf of an X.509 entry for a certificate of dnsNames.
+func leaf(t *testing.T, dnsNames []string) (leafInput []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// version, leaf_type, timestamp, entry_type, then the 24-bit length prefixed certificate.
+	leafInput = make([]byte, 12, 15+len(der))
+
+	binary.BigEndian.PutUint16(leafInput[10:12], entryTypeX509)
+
+	leafInput = append(leafInput, byte(len(der)>>16), byte(len(der)>>8), byte(len(der)))
+	leafInput = append(leafInput, der...)
+
+	return
+}
+
+func run(t *testing.T, cfg *sources.Configuration, domain string) (subdomains []string) {
+	t.Helper()
+
+	source := &Source{}
+
+	for result := range source.Run(context.Background(), cfg, domain) {
+		switch result.Type {
+		case sources.ResultSubdomain:
+			subdomains = append(subdomains, result.Value)
+		case sources.ResultError:
+			t.Errorf("Run() error = %v", result.Error)
+		}
+	}
+
+	slices.Sort(subdomains)
+
+	return
+}
+
+func TestSourceReadsLogAndCachesNames(t *testing.T) {
+	log := newStubLog(t,
+		[]string{"a.example.com", "*.b.example.com"},
+		[]string{"www.other.com"},
+		[]string{"a.example.com"},
+	)
+
+	cfg := &sources.Configuration{
+		CTLog: sources.CTLogConfiguration{
+			Logs:           []string{log.URL + "/"},
+			CacheDirectory: t.TempDir(),
+		},
+	}
+
+	got := run(t, cfg, "example.com")
+
+	if want := []string{"a.example.com", "a.example.com", "b.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("first run = %v, want %v", got, want)
+	}
+
+	requests := log.entriesRequests.Load()
+
+	// A re-scan, for another domain, reads the names cached, once each, and
+	// no entry, none being added since.
+	got = run(t, cfg, "other.com")
+
+	if want := []string{"www.other.com"}; !slices.Equal(got, want) {
+		t.Errorf("second run = %v, want %v", got, want)
+	}
+
+	if log.entriesRequests.Load() != requests {
+		t.Errorf("second run requested entries already processed")
+	}
+}
+
+func TestSourceReadsNewestEntriesFirstThenBackfills(t *testing.T) {
+	log := newStubLog(t, []string{"a.example.com"})
+
+	cfg := &sources.Configuration{
+		CTLog: sources.CTLogConfiguration{
+			Logs:           []string{log.URL},
+			MaxEntries:     2,
+			CacheDirectory: t.TempDir(),
+		},
+	}
+
+	run(t, cfg, "example.com")
+
+	log.add(t,
+		[]string{"b.example.com"},
+		[]string{"c.example.com"},
+		[]string{"d.example.com"},
+	)
+
+	// The most recent entries are read first, the older one is skipped.
+	got := run(t, cfg, "d.example.com")
+
+	if want := []string{"d.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("second run = %v, want %v", got, want)
+	}
+
+	got = run(t, cfg, "c.example.com")
+
+	if want := []string{"c.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("c.example.com read by the second run = %v, want %v", got, want)
+	}
+
+	log.add(t, []string{"e.example.com"})
+
+	// The entry added since is read, then the skipped one with what is left.
+	got = run(t, cfg, "example.com")
+
+	if want := []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com", "e.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("third run = %v, want %v", got, want)
+	}
+
+	requests := log.entriesRequests.Load()
+
+	run(t, cfg, "example.com")
+
+	if log.entriesRequests.Load() != requests {
+		t.Errorf("fourth run requested entries already processed")
+	}
+}
+
+func TestLogCacheCompacts(t *testing.T) {
+	directory := t.TempDir()
+
+	names := "a.example.com\nb.example.com\na.example.com\nc.example.com\n"
+
+	if err := os.WriteFile(filepath.Join(directory, "names.txt"), []byte(names), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := openLogCache(directory, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = cache.add([]string{"d.example.com", "b.example.com"}, state{End: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = cache.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(directory, "names.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "b.example.com\nc.example.com\nd.example.com\n"; string(data) != want {
+		t.Errorf("names.txt = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,171 @@
+package dnsdb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// saf is a line of the Streaming API Framing (SAF) used by DNSDB: either a
+// result object, or a condition telling how the stream ended.
+type saf struct {
+	Cond string `json:"cond"`
+	Msg  string `json:"msg"`
+	Obj  struct {
+		RRName    string `json:"rrname"`
+		TimeFirst int64  `json:"time_first"`
+		TimeLast  int64  `json:"time_last"`
+	} `json:"obj"`
+}
+
+type Source struct{}
+
+var ErrSearchFailed = errors.New("search failed")
+
+const (
+	// pageSize is the number of results requested per page.
+	pageSize        = 1000
+	defaultMaxPages = 10
+)
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		key, err := cfg.Keys.DNSDB.PickRandom()
+		if key == "" || err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		since, err := cfg.PassiveDNS.SinceTime()
+		if err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		maxPages := cfg.PassiveDNS.MaxPages
+
+		if maxPages < 1 {
+			maxPages = defaultMaxPages
+		}
+
+		// Flexible Search matches owner names against a glob, the fence drops
+		// names not seen since, server side.
+		searchReqURL := fmt.Sprintf("https://api.dnsdb.info/dnsdb/v2/glob/rrnames/*.%s./ANY?limit=%d", domain, pageSize)
+
+		if !since.IsZero() {
+			searchReqURL += fmt.Sprintf("&time_last_after=%d", since.Unix())
+		}
+
+		searchReqHeaders := map[string]string{
+			"Accept":    "application/x-ndjson",
+			"X-API-Key": key,
+		}
+
+		for page := range maxPages {
+			if ctx.Err() != nil {
+				return
+			}
+
+			var searchRes *http.Response
+
+			searchRes, err = httpclient.Get(ctx, fmt.Sprintf("%s&offset=%d", searchReqURL, page*pageSize), "", searchReqHeaders)
+			if err != nil {
+				source.error(err, results)
+
+				httpclient.DiscardResponse(searchRes)
+
+				return
+			}
+
+			limited := false
+
+			scanner := bufio.NewScanner(searchRes.Body)
+
+			for scanner.Scan() {
+				var line saf
+
+				if err = json.Unmarshal(scanner.Bytes(), &line); err != nil {
+					source.error(err, results)
+
+					continue
+				}
+
+				switch line.Cond {
+				case "limited":
+					// The page is full, more results may follow at the next offset.
+					limited = true
+
+					continue
+				case "failed":
+					source.error(fmt.Errorf("%w: %s", ErrSearchFailed, line.Msg), results)
+
+					continue
+				case "begin", "ongoing", "succeeded":
+					continue
+				}
+
+				subdomain := strings.TrimSuffix(line.Obj.RRName, ".")
+
+				if subdomain == "" {
+					continue
+				}
+
+				result := sources.Result{
+					Type:   sources.ResultSubdomain,
+					Source: source.Name(),
+					Value:  subdomain,
+				}
+
+				if line.Obj.TimeFirst > 0 {
+					result.FirstSeen = time.Unix(line.Obj.TimeFirst, 0).UTC()
+				}
+
+				if line.Obj.TimeLast > 0 {
+					result.LastSeen = time.Unix(line.Obj.TimeLast, 0).UTC()
+				}
+
+				results <- result
+			}
+
+			if err = scanner.Err(); err != nil {
+				source.error(err, results)
+			}
+
+			searchRes.Body.Close()
+
+			if !limited {
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.DNSDB
+}
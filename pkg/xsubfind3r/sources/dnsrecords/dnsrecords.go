@@ -0,0 +1,321 @@
+package dnsrecords
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+type Stage struct{}
+
+// job is a name and the types of records queried for it.
+type job struct {
+	name  string
+	types []dnsmessage.Type
+}
+
+const (
+	defaultMaxNames    = 1000
+	defaultConcurrency = 20
+	// maxCNAMEChain caps the number of CNAME records followed from a name.
+	maxCNAMEChain = 8
+)
+
+var (
+	// defaultSRVServices are used when no SRV services are configured.
+	defaultSRVServices = []string{
+		"_sip._tcp",
+		"_sip._udp",
+		"_sips._tcp",
+		"_autodiscover._tcp",
+		"_xmpp-client._tcp",
+		"_xmpp-server._tcp",
+		"_caldav._tcp",
+		"_carddav._tcp",
+		"_imaps._tcp",
+		"_submission._tcp",
+		"_ldap._tcp",
+		"_kerberos._tcp",
+	}
+
+	// nameTypes are the types of records queried for every name.
+	nameTypes = []dnsmessage.Type{
+		dnsmessage.TypeMX,
+		dnsmessage.TypeNS,
+		dnsmessage.TypeTXT,
+		dnsmessage.TypeCNAME,
+	}
+
+	ErrNoResolver = errors.New("no resolver configured")
+)
+
+func (stage *Stage) Run(ctx context.Context, cfg *sources.Configuration, domain string, subdomains []string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		if cfg.Resolver == nil {
+			stage.error(ErrNoResolver, results)
+
+			return
+		}
+
+		maxNames := cfg.DNSRecords.MaxNames
+
+		if maxNames < 1 {
+			maxNames = defaultMaxNames
+		}
+
+		concurrency := cfg.DNSRecords.Concurrency
+
+		if concurrency < 1 {
+			concurrency = defaultConcurrency
+		}
+
+		services := cfg.DNSRecords.SRVServices
+
+		if len(services) == 0 {
+			services = defaultSRVServices
+		}
+
+		jobs := []job{
+			{name: domain, types: nameTypes},
+			{name: "_dmarc." + domain, types: []dnsmessage.Type{dnsmessage.TypeTXT}},
+		}
+
+		for _, service := range services {
+			jobs = append(jobs, job{name: service + "." + domain, types: []dnsmessage.Type{dnsmessage.TypeSRV}})
+		}
+
+		for _, subdomain := range subdomains {
+			if subdomain == domain {
+				continue
+			}
+
+			if maxNames == 0 {
+				break
+			}
+
+			maxNames--
+
+			jobs = append(jobs,
+				job{name: subdomain, types: nameTypes},
+				job{name: "_dmarc." + subdomain, types: []dnsmessage.Type{dnsmessage.TypeTXT}},
+			)
+		}
+
+		queue := make(chan job)
+
+		// Failed queries, mostly timeouts, are reported once, as a count.
+		failures := &resolver.Failures{}
+
+		wg := &sync.WaitGroup{}
+
+		for range concurrency {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for j := range queue {
+					for _, qtype := range j.types {
+						stage.query(ctx, cfg, domain, j.name, qtype, failures, results)
+					}
+				}
+			}()
+		}
+
+	feed:
+		for _, j := range jobs {
+			select {
+			case <-ctx.Done():
+				break feed
+			case queue <- j:
+			}
+		}
+
+		close(queue)
+
+		wg.Wait()
+
+		if err := failures.Err(); err != nil {
+			stage.error(err, results)
+		}
+	}()
+
+	return results
+}
+
+// query queries the records of type qtype of name and reports the hostnames
+// they reference. CNAME chains are followed, whether in scope or not.
+func (stage *Stage) query(ctx context.Context, cfg *sources.Configuration, domain, name string, qtype dnsmessage.Type, failures *resolver.Failures, results chan sources.Result) {
+	for range maxCNAMEChain {
+		if ctx.Err() != nil {
+			return
+		}
+
+		msg, err := cfg.Resolver.Query(ctx, name, qtype)
+		if err != nil {
+			if ctx.Err() == nil {
+				failures.Add(err)
+			}
+
+			return
+		}
+
+		next := ""
+
+		for _, answer := range msg.Answers {
+			switch body := answer.Body.(type) {
+			case *dnsmessage.MXResource:
+				stage.hostname(domain, body.MX.String(), results)
+			case *dnsmessage.NSResource:
+				stage.hostname(domain, body.NS.String(), results)
+			case *dnsmessage.SRVResource:
+				stage.hostname(domain, body.Target.String(), results)
+			case *dnsmessage.CNAMEResource:
+				stage.hostname(domain, body.CNAME.String(), results)
+
+				if qtype == dnsmessage.TypeCNAME {
+					next = body.CNAME.String()
+				}
+			case *dnsmessage.TXTResource:
+				text := strings.Join(body.TXT, "")
+
+				for _, subdomain := range cfg.Extractor.FindAllString(text, -1) {
+					stage.hostname(domain, subdomain, results)
+				}
+
+				for _, hostname := range txtHostnames(text) {
+					stage.hostname(domain, hostname, results)
+				}
+			}
+		}
+
+		if next == "" {
+			return
+		}
+
+		name = next
+	}
+}
+
+// hostname reports hostname as a subdomain if it is under domain, or as a
+// related domain otherwise.
+func (stage *Stage) hostname(domain, hostname string, results chan sources.Result) {
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+
+	// The SRV target "." means the service is not available.
+	if !strings.Contains(hostname, ".") {
+		return
+	}
+
+	result := sources.Result{
+		Type:   sources.ResultSubdomain,
+		Source: stage.Name(),
+		Value:  hostname,
+	}
+
+	if hostname != domain && !strings.HasSuffix(hostname, "."+domain) {
+		result.Type = sources.ResultRelated
+	}
+
+	results <- result
+}
+
+// txtHostnames returns the hostnames referenced by SPF and DMARC records.
+func txtHostnames(text string) (hostnames []string) {
+	lower := strings.ToLower(text)
+
+	switch {
+	case strings.HasPrefix(lower, "v=spf1"):
+		hostnames = spfHostnames(lower)
+	case strings.HasPrefix(lower, "v=dmarc1"):
+		hostnames = dmarcHostnames(lower)
+	}
+
+	return
+}
+
+// spfHostnames returns the domains of the `include:`, `a:`, `mx:`, `ptr:`,
+// `exists:` mechanisms and `redirect=` and `exp=` modifiers of an SPF record
+// (RFC 7208). Domains built from macros, e.g. `%{i}.example.com`, are skipped.
+func spfHostnames(record string) (hostnames []string) {
+	for _, term := range strings.Fields(record)[1:] {
+		term = strings.TrimLeft(term, "+-~?")
+
+		separator := ":"
+
+		if strings.HasPrefix(term, "redirect=") || strings.HasPrefix(term, "exp=") {
+			separator = "="
+		}
+
+		mechanism, value, ok := strings.Cut(term, separator)
+		if !ok {
+			continue
+		}
+
+		switch mechanism {
+		case "include", "a", "mx", "ptr", "exists", "redirect", "exp":
+		default:
+			continue
+		}
+
+		// `a` and `mx` may carry a CIDR length, e.g. `a:mail.example.com/24`.
+		value, _, _ = strings.Cut(value, "/")
+
+		if value == "" || strings.Contains(value, "%") {
+			continue
+		}
+
+		hostnames = append(hostnames, value)
+	}
+
+	return
+}
+
+// dmarcHostnames returns the domains of the `rua` and `ruf` report addresses
+// of a DMARC record (RFC 7489), e.g. `rua=mailto:dmarc@example.com!10m`.
+func dmarcHostnames(record string) (hostnames []string) {
+	for _, tag := range strings.Split(record, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(tag), "=")
+		if !ok || (strings.TrimSpace(key) != "rua" && strings.TrimSpace(key) != "ruf") {
+			continue
+		}
+
+		for _, address := range strings.Split(value, ",") {
+			address = strings.TrimPrefix(strings.TrimSpace(address), "mailto:")
+
+			_, hostname, ok := strings.Cut(address, "@")
+			if !ok {
+				continue
+			}
+
+			hostname, _, _ = strings.Cut(hostname, "!")
+
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	return
+}
+
+func (stage *Stage) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: stage.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (stage *Stage) Name() string {
+	return sources.DNSRECORDS
+}
@@ -0,0 +1,163 @@
+package fofa
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type searchResponse struct {
+	Error   bool       `json:"error"`
+	ErrMsg  string     `json:"errmsg"`
+	Size    int        `json:"size"`
+	Results [][]string `json:"results"`
+}
+
+type Source struct{}
+
+const (
+	// pageSize is the number of results requested per page.
+	pageSize        = 100
+	defaultMaxPages = 10
+)
+
+var (
+	ErrMalformedKey = errors.New("malformed key, expected `email:key`")
+	ErrSearchFailed = errors.New("search failed")
+)
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		key, err := cfg.Keys.FOFA.PickRandom()
+		if key == "" || err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		// FOFA authenticates with the account email and its key, given as `email:key`.
+		fofaEmail, fofaKey, ok := strings.Cut(key, ":")
+		if !ok || fofaEmail == "" || fofaKey == "" || strings.Contains(fofaKey, ":") {
+			source.error(ErrMalformedKey, results)
+
+			return
+		}
+
+		maxPages := cfg.HostSearch.MaxPages
+
+		if maxPages < 1 {
+			maxPages = defaultMaxPages
+		}
+
+		query := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("domain=%q", domain)))
+
+		listed := 0
+
+		for page := 1; page <= maxPages; page++ {
+			if ctx.Err() != nil {
+				return
+			}
+
+			searchReqURL := fmt.Sprintf("https://fofa.info/api/v1/search/all?email=%s&key=%s&qbase64=%s&fields=host,domain&size=%d&page=%d",
+				url.QueryEscape(fofaEmail), url.QueryEscape(fofaKey), url.QueryEscape(query), pageSize, page)
+
+			var searchRes *http.Response
+
+			searchRes, err = httpclient.Get(ctx, searchReqURL, "", nil)
+			if err != nil {
+				source.error(err, results)
+
+				httpclient.DiscardResponse(searchRes)
+
+				return
+			}
+
+			var searchResData searchResponse
+
+			if err = json.NewDecoder(searchRes.Body).Decode(&searchResData); err != nil {
+				source.error(err, results)
+
+				searchRes.Body.Close()
+
+				return
+			}
+
+			searchRes.Body.Close()
+
+			if searchResData.Error {
+				source.error(fmt.Errorf("%w: %s", ErrSearchFailed, searchResData.ErrMsg), results)
+
+				return
+			}
+
+			for _, fields := range searchResData.Results {
+				for _, field := range fields {
+					source.hostname(domain, field, results)
+				}
+			}
+
+			listed += len(searchResData.Results)
+
+			if len(searchResData.Results) < pageSize || listed >= searchResData.Size {
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// hostname reports the hostname of host, which may be a URL, e.g.
+// "https://www.example.com:8443", if it is a subdomain of domain.
+func (source *Source) hostname(domain, host string, results chan sources.Result) {
+	if strings.Contains(host, "://") {
+		parsed, err := url.Parse(host)
+		if err != nil {
+			return
+		}
+
+		host = parsed.Host
+	}
+
+	host, _, _ = strings.Cut(host, ":")
+
+	subdomain := strings.ToLower(host)
+
+	if subdomain != domain && !strings.HasSuffix(subdomain, "."+domain) {
+		return
+	}
+
+	result := sources.Result{
+		Type:   sources.ResultSubdomain,
+		Source: source.Name(),
+		Value:  subdomain,
+	}
+
+	results <- result
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.FOFA
+}
@@ -2,6 +2,7 @@ package github
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -31,7 +32,7 @@ type searchResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -45,18 +46,22 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 
 		searchReqURL := fmt.Sprintf("https://api.github.com/search/code?per_page=100&q=%q&sort=created&order=asc", domain)
 
-		source.Enumerate(searchReqURL, cfg.Extractor, tokens, results, cfg)
+		source.Enumerate(ctx, domain, searchReqURL, cfg.Extractor, tokens, results, cfg)
 	}()
 
 	return results
 }
 
-func (source *Source) Enumerate(searchReqURL string, domainRegexp *regexp.Regexp, tokens *Tokens, results chan sources.Result, config *sources.Configuration) {
+func (source *Source) Enumerate(ctx context.Context, domain, searchReqURL string, domainRegexp *regexp.Regexp, tokens *Tokens, results chan sources.Result, config *sources.Configuration) {
 	token := tokens.Get()
 
 	if token.RetryAfter > 0 {
 		if len(tokens.pool) == 1 {
-			time.Sleep(time.Duration(token.RetryAfter) * time.Second)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(token.RetryAfter) * time.Second):
+			}
 		} else {
 			token = tokens.Get()
 		}
@@ -71,7 +76,7 @@ func (source *Source) Enumerate(searchReqURL string, domainRegexp *regexp.Regexp
 
 	var searchRes *http.Response
 
-	searchRes, err = httpclient.Get(searchReqURL, "", searchReqHeaders)
+	searchRes, err = httpclient.Get(ctx, searchReqURL, "", searchReqHeaders)
 
 	isForbidden := searchRes != nil && searchRes.StatusCode == status.Forbidden
 
@@ -95,7 +100,7 @@ func (source *Source) Enumerate(searchReqURL string, domainRegexp *regexp.Regexp
 
 		tokens.setCurrentTokenExceeded(retryAfterSeconds)
 
-		source.Enumerate(searchReqURL, domainRegexp, tokens, results, config)
+		source.Enumerate(ctx, domain, searchReqURL, domainRegexp, tokens, results, config)
 	}
 
 	var searchResData searchResponse
@@ -121,7 +126,7 @@ func (source *Source) Enumerate(searchReqURL string, domainRegexp *regexp.Regexp
 
 		var getRawContentRes *http.Response
 
-		getRawContentRes, err = httpclient.SimpleGet(getRawContentReqURL)
+		getRawContentRes, err = httpclient.SimpleGet(ctx, getRawContentReqURL)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
@@ -159,6 +164,12 @@ func (source *Source) Enumerate(searchReqURL string, domainRegexp *regexp.Regexp
 
 				results <- result
 			}
+
+			// Hosts of URLs next to the target's subdomains, e.g. in the same
+			// configuration entry, are likely related to it.
+			if len(subdomains) > 0 {
+				source.related(domain, line, item.HTMLURL, results)
+			}
 		}
 
 		if err = scanner.Err(); err != nil {
@@ -209,7 +220,7 @@ func (source *Source) Enumerate(searchReqURL string, domainRegexp *regexp.Regexp
 				return
 			}
 
-			source.Enumerate(nextURL, domainRegexp, tokens, results, config)
+			source.Enumerate(ctx, domain, nextURL, domainRegexp, tokens, results, config)
 		}
 	}
 }
@@ -220,6 +231,25 @@ func getRawContentURL(htmlURL string) string {
 	return strings.ReplaceAll(domain, "/blob/", "/")
 }
 
+// related reports the hosts of the URLs in text that are outside the scope of
+// domain, with the file they were found in as evidence.
+func (source *Source) related(domain, text, file string, results chan sources.Result) {
+	for _, host := range sources.URLHosts(text) {
+		if sources.InScope(host, domain) {
+			continue
+		}
+
+		result := sources.Result{
+			Type:     sources.ResultRelated,
+			Source:   source.Name(),
+			Value:    host,
+			Evidence: file,
+		}
+
+		results <- result
+	}
+}
+
 func (source *Source) Name() string {
 	return sources.GITHUB
 }
@@ -0,0 +1,215 @@
+package gitlab
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hueristiq/hq-go-http/headers"
+	"github.com/hueristiq/hq-go-http/status"
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"github.com/spf13/cast"
+)
+
+type searchResponse []struct {
+	Data      string `json:"data"`
+	Path      string `json:"path"`
+	Ref       string `json:"ref"`
+	ProjectID int    `json:"project_id"`
+}
+
+type Source struct{}
+
+// defaultBaseURL is used when no base URL is configured.
+const defaultBaseURL = "https://gitlab.com"
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		if len(cfg.Keys.GitLab) == 0 {
+			return
+		}
+
+		tokens := NewTokenManager(cfg.Keys.GitLab)
+
+		baseURL := strings.TrimSuffix(cfg.GitLab.BaseURL, "/")
+
+		if baseURL == "" {
+			baseURL = defaultBaseURL
+		}
+
+		page := cast.ToInt(cfg.Cursor(domain, source.Name()))
+
+		if page < 1 {
+			page = 1
+		}
+
+		for page > 0 {
+			if ctx.Err() != nil {
+				return
+			}
+
+			searchReqURL := fmt.Sprintf("%s/api/v4/search?scope=blobs&search=%s&per_page=100&page=%d", baseURL, url.QueryEscape(domain), page)
+
+			var next int
+
+			var ok bool
+
+			next, ok = source.search(ctx, cfg, baseURL, searchReqURL, tokens, results)
+			if !ok {
+				return
+			}
+
+			if next > 0 {
+				cfg.SetCursor(domain, source.Name(), cast.ToString(next))
+			}
+
+			page = next
+		}
+	}()
+
+	return results
+}
+
+// search fetches a page of search results and the files they match, and
+// returns the number of the next page, 0 if it is the last one. Rate limited
+// requests are retried with the next token, or after the limit is reset.
+func (source *Source) search(ctx context.Context, cfg *sources.Configuration, baseURL, searchReqURL string, tokens *Tokens, results chan sources.Result) (next int, ok bool) {
+	token := tokens.Get()
+
+	for range len(tokens.pool) - 1 {
+		if token.RetryAfter == 0 {
+			break
+		}
+
+		token = tokens.Get()
+	}
+
+	// Every token is rate limited, wait until this one is reset.
+	if token.RetryAfter > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(token.ExceededTime.Add(time.Duration(token.RetryAfter) * time.Second))):
+		}
+	}
+
+	searchReqHeaders := map[string]string{
+		"PRIVATE-TOKEN": token.Hash,
+	}
+
+	searchRes, err := httpclient.Get(ctx, searchReqURL, "", searchReqHeaders)
+
+	isRateLimited := searchRes != nil && searchRes.StatusCode == status.TooManyRequests
+
+	if isRateLimited {
+		retryAfterSeconds := cast.ToInt64(searchRes.Header.Get(headers.RetryAfter))
+
+		if retryAfterSeconds == 0 {
+			retryAfterSeconds = 60
+		}
+
+		httpclient.DiscardResponse(searchRes)
+
+		tokens.setCurrentTokenExceeded(retryAfterSeconds)
+
+		return source.search(ctx, cfg, baseURL, searchReqURL, tokens, results)
+	}
+
+	if err != nil {
+		source.error(err, results)
+
+		httpclient.DiscardResponse(searchRes)
+
+		return
+	}
+
+	var searchResData searchResponse
+
+	if err = json.NewDecoder(searchRes.Body).Decode(&searchResData); err != nil {
+		source.error(err, results)
+
+		searchRes.Body.Close()
+
+		return
+	}
+
+	searchRes.Body.Close()
+
+	for _, blob := range searchResData {
+		for _, subdomain := range cfg.Extractor.FindAllString(blob.Data, -1) {
+			source.subdomain(subdomain, results)
+		}
+
+		// The blob data is only the matching snippet, the whole file may name more subdomains.
+		getRawContentReqURL := fmt.Sprintf("%s/api/v4/projects/%d/repository/files/%s/raw?ref=%s", baseURL, blob.ProjectID, url.PathEscape(blob.Path), url.QueryEscape(blob.Ref))
+
+		var getRawContentRes *http.Response
+
+		getRawContentRes, err = httpclient.Get(ctx, getRawContentReqURL, "", searchReqHeaders)
+		if err != nil {
+			source.error(err, results)
+
+			httpclient.DiscardResponse(getRawContentRes)
+
+			continue
+		}
+
+		scanner := bufio.NewScanner(getRawContentRes.Body)
+
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				continue
+			}
+
+			for _, subdomain := range cfg.Extractor.FindAllString(line, -1) {
+				source.subdomain(subdomain, results)
+			}
+		}
+
+		if err = scanner.Err(); err != nil {
+			source.error(err, results)
+		}
+
+		getRawContentRes.Body.Close()
+	}
+
+	next = cast.ToInt(searchRes.Header.Get("X-Next-Page"))
+	ok = true
+
+	return
+}
+
+func (source *Source) subdomain(subdomain string, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultSubdomain,
+		Source: source.Name(),
+		Value:  subdomain,
+	}
+
+	results <- result
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.GITLAB
+}
@@ -0,0 +1,62 @@
+package gitlab
+
+import "time"
+
+type Token struct {
+	Hash         string
+	RetryAfter   int64
+	ExceededTime time.Time
+}
+
+type Tokens struct {
+	current int
+	pool    []Token
+}
+
+func NewTokenManager(keys []string) *Tokens {
+	pool := []Token{}
+
+	for _, key := range keys {
+		t := Token{Hash: key, ExceededTime: time.Time{}, RetryAfter: 0}
+		pool = append(pool, t)
+	}
+
+	return &Tokens{
+		current: 0,
+		pool:    pool,
+	}
+}
+
+// setCurrentTokenExceeded marks the token last returned by Get as rate limited.
+func (r *Tokens) setCurrentTokenExceeded(retryAfter int64) {
+	last := (r.current + len(r.pool) - 1) % len(r.pool)
+
+	if r.pool[last].RetryAfter == 0 {
+		r.pool[last].ExceededTime = time.Now()
+		r.pool[last].RetryAfter = retryAfter
+	}
+}
+
+func (r *Tokens) Get() *Token {
+	resetExceededTokens(r)
+
+	if r.current >= len(r.pool) {
+		r.current %= len(r.pool)
+	}
+
+	result := &r.pool[r.current]
+	r.current++
+
+	return result
+}
+
+func resetExceededTokens(r *Tokens) {
+	for i, token := range r.pool {
+		if token.RetryAfter > 0 {
+			if int64(time.Since(token.ExceededTime)/time.Second) > token.RetryAfter {
+				r.pool[i].ExceededTime = time.Time{}
+				r.pool[i].RetryAfter = 0
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package hackertarget
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 
 	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
@@ -10,7 +11,7 @@ import (
 
 type Source struct{}
 
-func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -18,7 +19,7 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 
 		hostSearchReqURL := fmt.Sprintf("https://api.hackertarget.com/hostsearch/?q=%s", domain)
 
-		hostSearchRes, err := httpclient.SimpleGet(hostSearchReqURL)
+		hostSearchRes, err := httpclient.SimpleGet(ctx, hostSearchReqURL)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
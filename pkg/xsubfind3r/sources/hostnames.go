@@ -0,0 +1,68 @@
+package sources
+
+import (
+	"crypto/x509"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlHostRegex matches the hosts of absolute URLs, e.g. "partner.example.net"
+// in "https://partner.example.net:8443/path".
+var urlHostRegex = regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://(?:[^/\s@"'<>]*@)?([a-z0-9_-]+(?:\.[a-z0-9_-]+)+)`)
+
+// URLHosts returns the lowercased hosts of the absolute URLs in text, including
+// those URL encoded in it, e.g. in the query of a redirect URL.
+func URLHosts(text string) (hosts []string) {
+	if unescaped, err := url.QueryUnescape(text); err == nil {
+		text = unescaped
+	}
+
+	seen := map[string]struct{}{}
+
+	for _, match := range urlHostRegex.FindAllStringSubmatch(text, -1) {
+		host := strings.ToLower(match[1])
+
+		if _, ok := seen[host]; ok {
+			continue
+		}
+
+		seen[host] = struct{}{}
+
+		hosts = append(hosts, host)
+	}
+
+	return
+}
+
+// InScope reports whether hostname is domain or one of its subdomains.
+func InScope(hostname, domain string) bool {
+	return hostname == domain || strings.HasSuffix(hostname, "."+domain)
+}
+
+// CertificateNames returns the lowercased SAN DNS names and CN of certificate,
+// with wildcard labels removed. Names without a dot, e.g. "localhost", and
+// CNs that are not hostnames are dropped.
+func CertificateNames(certificate *x509.Certificate) (names []string) {
+	candidates := append([]string{certificate.Subject.CommonName}, certificate.DNSNames...)
+
+	seen := map[string]struct{}{}
+
+	for _, name := range candidates {
+		name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "*.")
+
+		if name == "" || !strings.Contains(name, ".") || strings.ContainsAny(name, " /:@") {
+			continue
+		}
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		seen[name] = struct{}{}
+
+		names = append(names, name)
+	}
+
+	return
+}
@@ -0,0 +1,30 @@
+package sources_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"slices"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+func TestCertificateNames(t *testing.T) {
+	certificate := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "WWW.Example.com"},
+		DNSNames: []string{
+			"www.example.com",
+			"*.api.example.com",
+			"localhost",
+			"Plesk Default",
+			"mail@example.com",
+			"",
+		},
+	}
+
+	want := []string{"www.example.com", "api.example.com"}
+
+	if got := sources.CertificateNames(certificate); !slices.Equal(got, want) {
+		t.Errorf("CertificateNames() = %v, want %v", got, want)
+	}
+}
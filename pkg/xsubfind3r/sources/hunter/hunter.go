@@ -0,0 +1,144 @@
+package hunter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type searchResponse struct {
+	Code int `json:"code"`
+	Data struct {
+		Total int `json:"total"`
+		List  []struct {
+			Domain string `json:"domain"`
+		} `json:"list"`
+	} `json:"data"`
+	Message string `json:"message"`
+}
+
+type Source struct{}
+
+const (
+	// pageSize is the number of results requested per page.
+	pageSize        = 100
+	defaultMaxPages = 10
+	// searchWindow is the time range searched, ending today: Hunter.how
+	// requires one and caps its length to a year.
+	searchWindow = 365 * 24 * time.Hour
+)
+
+var ErrSearchFailed = errors.New("search failed")
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		key, err := cfg.Keys.Hunter.PickRandom()
+		if key == "" || err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		maxPages := cfg.HostSearch.MaxPages
+
+		if maxPages < 1 {
+			maxPages = defaultMaxPages
+		}
+
+		query := base64.URLEncoding.EncodeToString([]byte(fmt.Sprintf("domain.suffix=%q", domain)))
+
+		end := time.Now().UTC()
+		start := end.Add(-searchWindow)
+
+		listed := 0
+
+		for page := 1; page <= maxPages; page++ {
+			if ctx.Err() != nil {
+				return
+			}
+
+			searchReqURL := fmt.Sprintf("https://api.hunter.how/search?api-key=%s&query=%s&page=%d&page_size=%d&start_time=%s&end_time=%s",
+				url.QueryEscape(key), query, page, pageSize, start.Format(time.DateOnly), end.Format(time.DateOnly))
+
+			var searchRes *http.Response
+
+			searchRes, err = httpclient.Get(ctx, searchReqURL, "", nil)
+			if err != nil {
+				source.error(err, results)
+
+				httpclient.DiscardResponse(searchRes)
+
+				return
+			}
+
+			var searchResData searchResponse
+
+			if err = json.NewDecoder(searchRes.Body).Decode(&searchResData); err != nil {
+				source.error(err, results)
+
+				searchRes.Body.Close()
+
+				return
+			}
+
+			searchRes.Body.Close()
+
+			if searchResData.Code != http.StatusOK {
+				source.error(fmt.Errorf("%w: %s", ErrSearchFailed, searchResData.Message), results)
+
+				return
+			}
+
+			for _, record := range searchResData.Data.List {
+				subdomain := strings.ToLower(record.Domain)
+
+				if subdomain != domain && !strings.HasSuffix(subdomain, "."+domain) {
+					continue
+				}
+
+				result := sources.Result{
+					Type:   sources.ResultSubdomain,
+					Source: source.Name(),
+					Value:  subdomain,
+				}
+
+				results <- result
+			}
+
+			listed += len(searchResData.Data.List)
+
+			if len(searchResData.Data.List) < pageSize || listed >= searchResData.Data.Total {
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.HUNTER
+}
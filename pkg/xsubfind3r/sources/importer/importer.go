@@ -0,0 +1,439 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type Source struct{}
+
+// format is a kind of file the source imports.
+type format string
+
+const (
+	formatHAR       format = "har"
+	formatBurp      format = "burp"
+	formatNmap      format = "nmap"
+	formatJSONLines format = "jsonl"
+	formatText      format = "text"
+)
+
+const (
+	// sniffLength is the number of leading bytes read to detect the format of a file.
+	sniffLength = 4096
+	// maxLineLength caps the length of lines of line-oriented files.
+	maxLineLength = 1 << 20
+)
+
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL     string `json:"url"`
+				Headers []struct {
+					Value string `json:"value"`
+				} `json:"headers"`
+			} `json:"request"`
+			Response struct {
+				Headers []struct {
+					Value string `json:"value"`
+				} `json:"headers"`
+				Content struct {
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+				RedirectURL string `json:"redirectURL"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type burpItem struct {
+	URL      string   `xml:"url"`
+	Host     string   `xml:"host"`
+	Request  burpData `xml:"request"`
+	Response burpData `xml:"response"`
+}
+
+type burpData struct {
+	Base64 bool   `xml:"base64,attr"`
+	Data   string `xml:",chardata"`
+}
+
+// jsonLine holds the fields naming hosts in the JSON lines of subfinder
+// (`host`) and Amass (`name`).
+type jsonLine struct {
+	Host string `json:"host"`
+	Name string `json:"name"`
+}
+
+var ErrUnknownFormat = errors.New("unknown file format")
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		for _, path := range cfg.Import.Files {
+			if ctx.Err() != nil {
+				return
+			}
+
+			i := &importer{
+				cfg:     cfg,
+				source:  source,
+				domain:  domain,
+				path:    path,
+				seen:    map[string]struct{}{},
+				results: results,
+			}
+
+			if err := i.file(ctx); err != nil {
+				source.error(fmt.Errorf("%s: %w", path, err), results)
+			}
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.IMPORT
+}
+
+type importer struct {
+	cfg    *sources.Configuration
+	source *Source
+	domain string
+	path   string
+
+	// seen holds the names already reported from the file.
+	seen map[string]struct{}
+
+	results chan sources.Result
+}
+
+// file detects the format of the file and imports the hostnames it holds.
+func (i *importer) file(ctx context.Context) (err error) {
+	var file *os.File
+
+	file, err = os.Open(i.path)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	head, _ := reader.Peek(sniffLength)
+
+	switch detect(i.path, head) {
+	case formatHAR:
+		err = i.har(reader)
+	case formatBurp:
+		err = i.burp(ctx, reader)
+	case formatNmap:
+		err = i.nmap(ctx, reader)
+	case formatJSONLines:
+		err = i.jsonLines(ctx, reader)
+	case formatText:
+		err = i.text(ctx, reader)
+	default:
+		err = ErrUnknownFormat
+	}
+
+	return
+}
+
+// detect tells the format of a file from its extension and first bytes.
+func detect(path string, head []byte) format {
+	trimmed := bytes.TrimSpace(head)
+
+	switch {
+	case strings.EqualFold(filepath.Ext(path), ".har"):
+		return formatHAR
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		// Burp Suite exports items under `<items>`, Nmap writes `<nmaprun>`.
+		switch {
+		case bytes.Contains(head, []byte("<items")):
+			return formatBurp
+		case bytes.Contains(head, []byte("<nmaprun")):
+			return formatNmap
+		}
+
+		return ""
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		// A HAR is a single JSON document starting with its `log` object.
+		if bytes.HasPrefix(bytes.Join(bytes.Fields(trimmed[1:]), nil), []byte(`"log"`)) {
+			return formatHAR
+		}
+
+		return formatJSONLines
+	}
+
+	return formatText
+}
+
+// har imports the hosts of the requested and redirected to URLs of a HAR
+// file, and the names in the headers and response bodies it recorded.
+func (i *importer) har(reader io.Reader) (err error) {
+	var data harFile
+
+	if err = json.NewDecoder(reader).Decode(&data); err != nil {
+		return
+	}
+
+	for _, entry := range data.Log.Entries {
+		i.url(entry.Request.URL)
+		i.url(entry.Response.RedirectURL)
+
+		for _, header := range entry.Request.Headers {
+			i.extract(header.Value)
+		}
+
+		for _, header := range entry.Response.Headers {
+			i.extract(header.Value)
+		}
+
+		text := entry.Response.Content.Text
+
+		if entry.Response.Content.Encoding == "base64" {
+			if decoded, err := base64.StdEncoding.DecodeString(text); err == nil {
+				text = string(decoded)
+			}
+		}
+
+		i.extract(text)
+	}
+
+	return
+}
+
+// burp imports the hosts of the items of a Burp Suite XML export, and the
+// names in their requests and responses, base64 encoded or not.
+func (i *importer) burp(ctx context.Context, reader io.Reader) (err error) {
+	decoder := xml.NewDecoder(reader)
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var token xml.Token
+
+		token, err = decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "item" {
+			continue
+		}
+
+		var item burpItem
+
+		if err = decoder.DecodeElement(&item, &start); err != nil {
+			return
+		}
+
+		i.hostname(item.Host)
+		i.url(item.URL)
+
+		for _, data := range []burpData{item.Request, item.Response} {
+			text := data.Data
+
+			if data.Base64 {
+				if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text)); err == nil {
+					text = string(decoded)
+				}
+			}
+
+			i.extract(text)
+		}
+	}
+}
+
+// nmap imports the `hostname` elements of an Nmap XML report, user given and
+// PTR alike, and the names in the output of scripts, e.g. `ssl-cert`.
+func (i *importer) nmap(ctx context.Context, reader io.Reader) (err error) {
+	decoder := xml.NewDecoder(reader)
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var token xml.Token
+
+		token, err = decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		for _, attribute := range start.Attr {
+			switch {
+			case start.Name.Local == "hostname" && attribute.Name.Local == "name":
+				i.hostname(attribute.Value)
+			case start.Name.Local == "script" && attribute.Name.Local == "output":
+				i.extract(attribute.Value)
+			}
+		}
+	}
+}
+
+// jsonLines imports the hosts of JSON lines files, e.g. subfinder's `-oJ`
+// or Amass's `-json` output. Lines that are not JSON are read as text.
+func (i *importer) jsonLines(ctx context.Context, reader io.Reader) (err error) {
+	scanner := bufio.NewScanner(reader)
+
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineLength)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var line jsonLine
+
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			i.line(scanner.Text())
+
+			continue
+		}
+
+		i.hostname(line.Host)
+		i.hostname(line.Name)
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+// text imports the hosts of text files, one per line: plain host or URL lists
+// and massdns output, e.g. `www.example.com. CNAME example.cdn.net.`.
+func (i *importer) text(ctx context.Context, reader io.Reader) (err error) {
+	scanner := bufio.NewScanner(reader)
+
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineLength)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		i.line(scanner.Text())
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+// line imports the host a line starts with and, for massdns records pointing
+// to other names, e.g. CNAME or MX, the name it points to.
+func (i *importer) line(line string) {
+	fields := strings.Fields(line)
+
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return
+	}
+
+	if strings.Contains(fields[0], "://") {
+		i.url(fields[0])
+	} else {
+		i.hostname(fields[0])
+	}
+
+	if len(fields) >= 3 {
+		switch strings.ToUpper(fields[1]) {
+		case "CNAME", "NS", "MX", "PTR", "SRV":
+			i.hostname(fields[len(fields)-1])
+		}
+	}
+}
+
+// url imports the host of rawURL.
+func (i *importer) url(rawURL string) {
+	if rawURL == "" {
+		return
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+
+	i.hostname(parsed.Hostname())
+}
+
+// extract imports the names in text.
+func (i *importer) extract(text string) {
+	for _, name := range i.cfg.Extractor.FindAllString(text, -1) {
+		i.hostname(name)
+	}
+}
+
+// hostname reports hostname if it is the domain or one of its subdomains.
+// Imported files often hold other domains, those are skipped.
+func (i *importer) hostname(hostname string) {
+	hostname = strings.TrimPrefix(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), "."), "*.")
+
+	if hostname != i.domain && !strings.HasSuffix(hostname, "."+i.domain) {
+		return
+	}
+
+	if _, ok := i.seen[hostname]; ok {
+		return
+	}
+
+	i.seen[hostname] = struct{}{}
+
+	result := sources.Result{
+		Type:     sources.ResultSubdomain,
+		Source:   i.source.Name(),
+		Value:    hostname,
+		Evidence: i.path,
+	}
+
+	i.results <- result
+}
@@ -2,6 +2,7 @@ package intelx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -37,7 +38,7 @@ type getResultsResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -97,7 +98,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 
 		var searchRes *http.Response
 
-		searchRes, err = httpclient.Post(searchReqURL, "", searchReqHeaders, bytes.NewBuffer(searchReqBodyBytes))
+		searchRes, err = httpclient.Post(ctx, searchReqURL, "", searchReqHeaders, bytes.NewBuffer(searchReqBodyBytes))
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
@@ -136,7 +137,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 		for status == 0 || status == 3 {
 			var getResultsRes *http.Response
 
-			getResultsRes, err = httpclient.Get(getResultsReqURL, "", nil)
+			getResultsRes, err = httpclient.Get(ctx, getResultsReqURL, "", nil)
 			if err != nil {
 				result := sources.Result{
 					Type:   sources.ResultError,
@@ -0,0 +1,367 @@
+package localfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type Source struct{}
+
+// blob is a git object to search, with the path and commit it was first seen in.
+type blob struct {
+	hash   string
+	path   string
+	commit string
+}
+
+const (
+	defaultMaxSize = 5 << 20
+	// binarySniffLength is the number of leading bytes checked for a NUL
+	// byte to tell binary content apart, as git itself does.
+	binarySniffLength = 8000
+)
+
+var ErrUnexpectedGitOutput = errors.New("unexpected git output")
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		if len(cfg.LocalFS.Paths) == 0 {
+			return
+		}
+
+		s := &scanner{
+			cfg:     cfg,
+			source:  source,
+			maxSize: cfg.LocalFS.MaxSize,
+			seen:    map[string]struct{}{},
+			results: results,
+		}
+
+		if s.maxSize < 1 {
+			s.maxSize = defaultMaxSize
+		}
+
+		for _, path := range cfg.LocalFS.Paths {
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.walk(ctx, path)
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.LOCALFS
+}
+
+type scanner struct {
+	cfg    *sources.Configuration
+	source *Source
+
+	maxSize int64
+
+	// seen holds the names already reported, each is reported with the first file it was found in.
+	seen map[string]struct{}
+
+	results chan sources.Result
+}
+
+// walk searches the file at root or, if it is a directory, the files under it.
+// With history enabled, the history of every git repository met is searched too.
+func (s *scanner) walk(ctx context.Context, root string) {
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			s.source.error(err, s.results)
+
+			// An unreadable directory is skipped, the rest of the tree is still walked.
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				if s.cfg.LocalFS.History {
+					s.history(ctx, filepath.Dir(path))
+				}
+
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		s.file(path)
+
+		return nil
+	})
+
+	if err != nil && ctx.Err() == nil {
+		s.source.error(err, s.results)
+	}
+}
+
+// file searches the file at path, unless it is too large or binary.
+func (s *scanner) file(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		s.source.error(err, s.results)
+
+		return
+	}
+
+	if info.Size() > s.maxSize {
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		s.source.error(err, s.results)
+
+		return
+	}
+
+	s.extract(content, path)
+}
+
+// history searches every blob ever committed to the git repository at
+// repository, on any branch or tag, with the path and commit that
+// introduced it as evidence.
+func (s *scanner) history(ctx context.Context, repository string) {
+	blobs, err := listBlobs(ctx, repository)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.source.error(fmt.Errorf("%s: %w", repository, err), s.results)
+		}
+
+		return
+	}
+
+	if len(blobs) == 0 {
+		return
+	}
+
+	err = readBlobs(ctx, repository, blobs, s.maxSize, func(b blob, content []byte) {
+		s.extract(content, fmt.Sprintf("%s @ %s", filepath.Join(repository, b.path), b.commit))
+	})
+	if err != nil && ctx.Err() == nil {
+		s.source.error(fmt.Errorf("%s: %w", repository, err), s.results)
+	}
+}
+
+// extract reports the names in content, with evidence, unless content is binary.
+func (s *scanner) extract(content []byte, evidence string) {
+	if bytes.IndexByte(content[:min(len(content), binarySniffLength)], 0) != -1 {
+		return
+	}
+
+	for _, name := range s.cfg.Extractor.FindAllString(string(content), -1) {
+		name = strings.ToLower(name)
+
+		if _, ok := s.seen[name]; ok {
+			continue
+		}
+
+		s.seen[name] = struct{}{}
+
+		result := sources.Result{
+			Type:     sources.ResultSubdomain,
+			Source:   s.source.Name(),
+			Value:    name,
+			Evidence: evidence,
+		}
+
+		s.results <- result
+	}
+}
+
+// listBlobs lists the blobs added or modified by the commits of repository,
+// each with the first commit and path it appeared with.
+func listBlobs(ctx context.Context, repository string) (blobs []blob, err error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", repository, "-c", "core.quotePath=false",
+		"log", "--all", "--raw", "--no-abbrev", "--no-renames", "--format=commit %H")
+
+	var output []byte
+
+	output, err = cmd.Output()
+	if err != nil {
+		return
+	}
+
+	seen := map[string]struct{}{}
+
+	commit := ""
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if hash, ok := strings.CutPrefix(line, "commit "); ok {
+			commit = hash
+
+			continue
+		}
+
+		// Raw diff lines read `:<old mode> <new mode> <old hash> <new hash> <status>\t<path>`.
+		if !strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		meta, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(meta)
+		if len(fields) < 5 {
+			continue
+		}
+
+		hash := fields[3]
+
+		// Deleted files have no new blob.
+		if strings.Trim(hash, "0") == "" {
+			continue
+		}
+
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+
+		seen[hash] = struct{}{}
+
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+
+		blobs = append(blobs, blob{hash: hash, path: path, commit: commit})
+	}
+
+	return
+}
+
+// readBlobs streams blobs from repository through `git cat-file --batch`,
+// calling found with the content of each one no larger than maxSize.
+func readBlobs(ctx context.Context, repository string, blobs []blob, maxSize int64, found func(b blob, content []byte)) (err error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", repository, "cat-file", "--batch")
+
+	var stdin io.WriteCloser
+
+	stdin, err = cmd.StdinPipe()
+	if err != nil {
+		return
+	}
+
+	var stdout io.ReadCloser
+
+	stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+
+	if err = cmd.Start(); err != nil {
+		return
+	}
+
+	go func() {
+		defer stdin.Close()
+
+		writer := bufio.NewWriter(stdin)
+
+		for _, b := range blobs {
+			fmt.Fprintln(writer, b.hash)
+		}
+
+		writer.Flush()
+	}()
+
+	reader := bufio.NewReader(stdout)
+
+	for _, b := range blobs {
+		var header string
+
+		header, err = reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+
+		// Headers read `<hash> <type> <size>`, or `<hash> missing`.
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+
+		var size int64
+
+		size, err = strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			err = fmt.Errorf("%w: %q", ErrUnexpectedGitOutput, header)
+
+			break
+		}
+
+		// The content is followed by a newline.
+		if size > maxSize {
+			if _, err = reader.Discard(int(size) + 1); err != nil {
+				break
+			}
+
+			continue
+		}
+
+		content := make([]byte, size+1)
+
+		if _, err = io.ReadFull(reader, content); err != nil {
+			break
+		}
+
+		found(b, content[:size])
+	}
+
+	// A reading error leaves git blocked on writing the rest of the blobs.
+	if err != nil {
+		_ = cmd.Process.Kill()
+	}
+
+	if waitErr := cmd.Wait(); err == nil {
+		err = waitErr
+	}
+
+	return
+}
@@ -0,0 +1,151 @@
+package mnemonic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type queryResponse struct {
+	Count int `json:"count"`
+	Data  []struct {
+		Query              string `json:"query"`
+		Answer             string `json:"answer"`
+		RRType             string `json:"rrtype"`
+		FirstSeenTimestamp int64  `json:"firstSeenTimestamp"`
+		LastSeenTimestamp  int64  `json:"lastSeenTimestamp"`
+	} `json:"data"`
+}
+
+type Source struct{}
+
+const (
+	// pageSize is the number of records requested per page.
+	pageSize        = 1000
+	defaultMaxPages = 10
+)
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		// Mnemonic serves anonymous requests too, under a lower quota.
+		key, _ := cfg.Keys.Mnemonic.PickRandom()
+
+		// Mnemonic takes no time range, records last seen before the fence are dropped here.
+		since, err := cfg.PassiveDNS.SinceTime()
+		if err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		maxPages := cfg.PassiveDNS.MaxPages
+
+		if maxPages < 1 {
+			maxPages = defaultMaxPages
+		}
+
+		queryReqHeaders := map[string]string{
+			"Accept": "application/json",
+		}
+
+		if key != "" {
+			queryReqHeaders["Argus-API-Key"] = key
+		}
+
+		for page := range maxPages {
+			if ctx.Err() != nil {
+				return
+			}
+
+			queryReqURL := fmt.Sprintf("https://api.mnemonic.no/pdns/v3/%s?limit=%d&offset=%d&aggregateResult=true&includeAnonymousResults=true", domain, pageSize, page*pageSize)
+
+			var queryRes *http.Response
+
+			queryRes, err = httpclient.Get(ctx, queryReqURL, "", queryReqHeaders)
+			if err != nil {
+				source.error(err, results)
+
+				httpclient.DiscardResponse(queryRes)
+
+				return
+			}
+
+			var queryResData queryResponse
+
+			if err = json.NewDecoder(queryRes.Body).Decode(&queryResData); err != nil {
+				source.error(err, results)
+
+				queryRes.Body.Close()
+
+				return
+			}
+
+			queryRes.Body.Close()
+
+			for _, record := range queryResData.Data {
+				// Timestamps are in milliseconds.
+				lastSeen := time.UnixMilli(record.LastSeenTimestamp).UTC()
+
+				if !since.IsZero() && lastSeen.Before(since) {
+					continue
+				}
+
+				names := []string{record.Query}
+
+				// Records pointing to names, e.g. CNAME, may point to other subdomains.
+				switch strings.ToUpper(record.RRType) {
+				case "CNAME", "NS", "MX", "PTR":
+					names = append(names, record.Answer)
+				}
+
+				for _, name := range names {
+					name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+					if name != domain && !strings.HasSuffix(name, "."+domain) {
+						continue
+					}
+
+					result := sources.Result{
+						Type:      sources.ResultSubdomain,
+						Source:    source.Name(),
+						Value:     name,
+						FirstSeen: time.UnixMilli(record.FirstSeenTimestamp).UTC(),
+						LastSeen:  lastSeen,
+					}
+
+					results <- result
+				}
+			}
+
+			if (page+1)*pageSize >= queryResData.Count {
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.MNEMONIC
+}
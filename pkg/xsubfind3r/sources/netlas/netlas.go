@@ -0,0 +1,123 @@
+package netlas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type domainsResponse struct {
+	Items []struct {
+		Data struct {
+			Domain string `json:"domain"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+type Source struct{}
+
+const (
+	// pageSize is the number of items Netlas returns per page.
+	pageSize        = 20
+	defaultMaxPages = 10
+)
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		key, err := cfg.Keys.Netlas.PickRandom()
+		if key == "" || err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		maxPages := cfg.HostSearch.MaxPages
+
+		if maxPages < 1 {
+			maxPages = defaultMaxPages
+		}
+
+		query := url.QueryEscape("domain:*." + domain)
+
+		domainsReqHeaders := map[string]string{
+			"X-API-Key": key,
+		}
+
+		for page := range maxPages {
+			if ctx.Err() != nil {
+				return
+			}
+
+			domainsReqURL := fmt.Sprintf("https://app.netlas.io/api/domains/?q=%s&start=%d&fields=domain&source_type=include", query, page*pageSize)
+
+			var domainsRes *http.Response
+
+			domainsRes, err = httpclient.Get(ctx, domainsReqURL, "", domainsReqHeaders)
+			if err != nil {
+				source.error(err, results)
+
+				httpclient.DiscardResponse(domainsRes)
+
+				return
+			}
+
+			var domainsResData domainsResponse
+
+			if err = json.NewDecoder(domainsRes.Body).Decode(&domainsResData); err != nil {
+				source.error(err, results)
+
+				domainsRes.Body.Close()
+
+				return
+			}
+
+			domainsRes.Body.Close()
+
+			for _, item := range domainsResData.Items {
+				subdomain := strings.ToLower(item.Data.Domain)
+
+				if !strings.HasSuffix(subdomain, "."+domain) {
+					continue
+				}
+
+				result := sources.Result{
+					Type:   sources.ResultSubdomain,
+					Source: source.Name(),
+					Value:  subdomain,
+				}
+
+				results <- result
+			}
+
+			if len(domainsResData.Items) < pageSize {
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.NETLAS
+}
@@ -1,6 +1,7 @@
 package otx
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -19,7 +20,7 @@ type getPassiveDNSResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(_ *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, _ *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -27,7 +28,7 @@ func (source *Source) Run(_ *sources.Configuration, domain string) <-chan source
 
 		getPassiveDNSReqURL := fmt.Sprintf("https://otx.alienvault.com/api/v1/indicators/domain/%s/passive_dns", domain)
 
-		getPassiveDNSRes, err := httpclient.SimpleGet(getPassiveDNSReqURL)
+		getPassiveDNSRes, err := httpclient.SimpleGet(ctx, getPassiveDNSReqURL)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
@@ -0,0 +1,425 @@
+package permutation
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type Stage struct{}
+
+const (
+	defaultMaxTokens     = 20
+	defaultMaxCandidates = 10000
+	defaultConcurrency   = 50
+
+	// maxLabelLength and maxNameLength are the limits, in octets, of labels
+	// and names (RFC 1035), candidates over them cannot exist.
+	maxLabelLength = 63
+	maxNameLength  = 253
+)
+
+var (
+	// defaultWords are used when no words are configured.
+	defaultWords = []string{
+		"dev",
+		"stage",
+		"staging",
+		"test",
+		"qa",
+		"uat",
+		"prod",
+		"api",
+		"admin",
+		"internal",
+	}
+
+	ErrNoResolver = errors.New("no resolver configured")
+)
+
+func (stage *Stage) Run(ctx context.Context, cfg *sources.Configuration, domain string, subdomains []string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		if cfg.Resolver == nil {
+			stage.error(ErrNoResolver, results)
+
+			return
+		}
+
+		maxTokens := cfg.Permutation.MaxTokens
+
+		if maxTokens < 1 {
+			maxTokens = defaultMaxTokens
+		}
+
+		maxCandidates := cfg.Permutation.MaxCandidates
+
+		if maxCandidates < 1 {
+			maxCandidates = defaultMaxCandidates
+		}
+
+		concurrency := cfg.Permutation.Concurrency
+
+		if concurrency < 1 {
+			concurrency = defaultConcurrency
+		}
+
+		words := cfg.Permutation.Words
+
+		if len(words) == 0 {
+			words = defaultWords
+		}
+
+		g := newGenerator(domain, subdomains, words, maxTokens, maxCandidates)
+
+		candidates := g.generate()
+
+		wildcards := resolver.NewWildcards(cfg.Resolver)
+
+		names := make(chan string)
+
+		// Failed lookups, mostly timeouts, are reported once, as a count.
+		failures := &resolver.Failures{}
+
+		wg := &sync.WaitGroup{}
+
+		for range concurrency {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for name := range names {
+					addresses, err := cfg.Resolver.LookupHost(ctx, name)
+					if err != nil {
+						if ctx.Err() == nil {
+							failures.Add(err)
+						}
+
+						continue
+					}
+
+					if len(addresses) == 0 || wildcards.IsWildcard(ctx, domain, name, addresses) {
+						continue
+					}
+
+					result := sources.Result{
+						Type:   sources.ResultSubdomain,
+						Source: stage.Name(),
+						Value:  name,
+					}
+
+					results <- result
+				}
+			}()
+		}
+
+	feed:
+		for _, candidate := range candidates {
+			select {
+			case <-ctx.Done():
+				break feed
+			case names <- candidate:
+			}
+		}
+
+		close(names)
+
+		wg.Wait()
+
+		if err := failures.Err(); err != nil {
+			stage.error(err, results)
+		}
+	}()
+
+	return results
+}
+
+func (stage *Stage) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: stage.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (stage *Stage) Name() string {
+	return sources.PERMUTATION
+}
+
+// generator builds candidates out of the labels of found subdomains, i.e. the
+// part of a subdomain left of the target domain, e.g. `api2.dev` for
+// `api2.dev.example.com`.
+type generator struct {
+	domain string
+	// prefixes are the labels of found subdomains, split on dots.
+	prefixes [][]string
+	// words are the configured words followed by the learnt tokens.
+	words []string
+
+	maxCandidates int
+
+	seen       map[string]struct{}
+	candidates []string
+}
+
+// generate returns the candidates, deduplicated and without found subdomains.
+// Mutations are applied one kind at a time, over all subdomains, from the
+// most to the least likely to hit, so that the cap cuts the least likely.
+func (g *generator) generate() []string {
+	mutations := []func(labels []string){
+		g.numbers,
+		g.swaps,
+		g.dashes,
+		g.inserts,
+	}
+
+	for _, mutate := range mutations {
+		for _, labels := range g.prefixes {
+			if g.full() {
+				return g.candidates
+			}
+
+			mutate(labels)
+		}
+	}
+
+	return g.candidates
+}
+
+// numbers increments and decrements every number in every label, e.g.
+// `api2` gives `api1` and `api3`. Zero padding is kept, e.g. `web09` gives `web10`.
+func (g *generator) numbers(labels []string) {
+	for index, label := range labels {
+		for start := 0; start < len(label); {
+			if !isDigit(label[start]) {
+				start++
+
+				continue
+			}
+
+			end := start
+
+			for end < len(label) && isDigit(label[end]) {
+				end++
+			}
+
+			number, err := strconv.Atoi(label[start:end])
+			if err == nil {
+				for _, next := range []int{number - 1, number + 1} {
+					if next < 0 {
+						continue
+					}
+
+					digits := strconv.Itoa(next)
+
+					if padding := end - start - len(digits); padding > 0 {
+						digits = strings.Repeat("0", padding) + digits
+					}
+
+					g.add(replace(labels, index, label[:start]+digits+label[end:]))
+				}
+			}
+
+			start = end
+		}
+	}
+}
+
+// swaps replaces known words in labels, and dash separated parts of labels,
+// with the other words, e.g. `dev.api` gives `stage.api` and `dev-api` gives `stage-api`.
+func (g *generator) swaps(labels []string) {
+	for index, label := range labels {
+		parts := strings.Split(label, "-")
+
+		for position, part := range parts {
+			if !g.isWord(part) {
+				continue
+			}
+
+			for _, word := range g.words {
+				if word == part {
+					continue
+				}
+
+				swapped := append([]string{}, parts...)
+
+				swapped[position] = word
+
+				g.add(replace(labels, index, strings.Join(swapped, "-")))
+			}
+		}
+	}
+}
+
+// dashes joins words to the first label with a dash, e.g. `api` gives
+// `dev-api` and `api-dev`.
+func (g *generator) dashes(labels []string) {
+	for _, word := range g.words {
+		g.add(replace(labels, 0, word+"-"+labels[0]))
+		g.add(replace(labels, 0, labels[0]+"-"+word))
+	}
+}
+
+// inserts adds words as new labels, at every position, e.g. `api` gives
+// `dev.api` and `api.dev`.
+func (g *generator) inserts(labels []string) {
+	for _, word := range g.words {
+		for position := 0; position <= len(labels); position++ {
+			inserted := make([]string, 0, len(labels)+1)
+
+			inserted = append(inserted, labels[:position]...)
+			inserted = append(inserted, word)
+			inserted = append(inserted, labels[position:]...)
+
+			g.add(inserted)
+		}
+	}
+}
+
+func (g *generator) isWord(part string) bool {
+	for _, word := range g.words {
+		if word == part {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *generator) add(labels []string) {
+	if g.full() {
+		return
+	}
+
+	for _, label := range labels {
+		if len(label) > maxLabelLength {
+			return
+		}
+	}
+
+	candidate := strings.Join(labels, ".") + "." + g.domain
+
+	if len(candidate) > maxNameLength {
+		return
+	}
+
+	if _, ok := g.seen[candidate]; ok {
+		return
+	}
+
+	g.seen[candidate] = struct{}{}
+
+	g.candidates = append(g.candidates, candidate)
+}
+
+func (g *generator) full() bool {
+	return len(g.candidates) >= g.maxCandidates
+}
+
+// replace returns a copy of labels with the label at index replaced.
+func replace(labels []string, index int, label string) []string {
+	replaced := append([]string{}, labels...)
+
+	replaced[index] = label
+
+	return replaced
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// tokens returns the alphabetic tokens of labels, i.e. their dash separated
+// parts stripped of digits, e.g. `api2-eu` gives `api` and `eu`.
+func tokens(labels []string) (tokens []string) {
+	for _, label := range labels {
+		for _, part := range strings.Split(label, "-") {
+			token := strings.TrimFunc(part, unicode.IsDigit)
+
+			if len(token) > 1 && !strings.ContainsFunc(token, unicode.IsDigit) {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	return
+}
+
+func newGenerator(domain string, subdomains, words []string, maxTokens, maxCandidates int) (g *generator) {
+	g = &generator{
+		domain:        domain,
+		maxCandidates: maxCandidates,
+		seen:          map[string]struct{}{},
+	}
+
+	frequencies := map[string]int{}
+
+	for _, subdomain := range subdomains {
+		prefix, ok := strings.CutSuffix(subdomain, "."+domain)
+		if !ok || prefix == "" {
+			continue
+		}
+
+		g.seen[subdomain] = struct{}{}
+
+		labels := strings.Split(prefix, ".")
+
+		g.prefixes = append(g.prefixes, labels)
+
+		for _, token := range tokens(labels) {
+			frequencies[token]++
+		}
+	}
+
+	known := map[string]struct{}{}
+
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+
+		if _, ok := known[word]; ok || word == "" {
+			continue
+		}
+
+		known[word] = struct{}{}
+
+		g.words = append(g.words, word)
+	}
+
+	learnt := make([]string, 0, len(frequencies))
+
+	for token := range frequencies {
+		if _, ok := known[token]; !ok {
+			learnt = append(learnt, token)
+		}
+	}
+
+	// Most frequent first, ties broken alphabetically for stable output.
+	sort.Slice(learnt, func(i, j int) bool {
+		if frequencies[learnt[i]] != frequencies[learnt[j]] {
+			return frequencies[learnt[i]] > frequencies[learnt[j]]
+		}
+
+		return learnt[i] < learnt[j]
+	})
+
+	if len(learnt) > maxTokens {
+		learnt = learnt[:maxTokens]
+	}
+
+	g.words = append(g.words, learnt...)
+
+	return
+}
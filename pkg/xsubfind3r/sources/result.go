@@ -1,5 +1,7 @@
 package sources
 
+import "time"
+
 // Result represents the outcome of an operation or request, including the type of result,
 // the source of the data, the actual value retrieved (if applicable), and any error encountered.
 type Result struct {
@@ -7,6 +9,63 @@ type Result struct {
 	Source string     // Indicates the source from which the result was obtained (e.g., a specific API or service).
 	Value  string     // Holds the value of the result, such as a Subdomain or any other data returned from the operation.
 	Error  error      // Holds any error that occurred during the operation, or nil if no error occurred.
+
+	// Evidence, if set, tells where or how the value was found, e.g. the
+	// fingerprint of the certificate or the URL it was found in.
+	Evidence string
+
+	// FirstSeen and LastSeen, if set, are when the value was first and last
+	// observed by the source, e.g. in passive DNS data.
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// Subdomain, for IP address, CNAME and probe results, is the subdomain the
+	// address was found with, the name pointing to the canonical name or the
+	// subdomain probed.
+	Subdomain string
+
+	// Addresses, for subdomain results when enrichment is on, are the IP
+	// addresses of the subdomain and the networks they belong to.
+	Addresses []Address
+
+	// Response, for probe results, describes the response of the web server
+	// probed.
+	Response *Response
+
+	// Cluster, for probe results when clustering is on, is the cluster of
+	// similar responses the response belongs to.
+	Cluster *Cluster
+}
+
+// Address is an IP address of a subdomain, with the network it belongs to as
+// found in an ASN database, if any.
+type Address struct {
+	IP           string // The IP address, e.g. "93.184.216.34".
+	ASN          uint   // The number of the autonomous system announcing it, 0 if unknown.
+	Organization string // The organization operating the autonomous system.
+	Country      string // The ISO 3166-1 alpha-2 code of the country it is registered in.
+	CIDR         string // The network it belongs to, e.g. "93.184.216.0/24".
+}
+
+// Response describes the response of a web server to a probe, once redirects
+// were followed.
+type Response struct {
+	StatusCode    int    // The status code, e.g. 200.
+	Title         string // The title of the page, if HTML.
+	ContentLength int64  // The length of the body, as announced or else as read.
+	Server        string // The Server header.
+	FinalURL      string // The URL of the response, that of the last redirect followed.
+
+	SimHash           uint64 // The SimHash of the body, similar bodies have hashes differing in few bits.
+	HeaderFingerprint string // A hash of the names of the headers, volatile ones left out, and of the Server header.
+}
+
+// Cluster is a group of web servers whose responses are similar, e.g. the
+// same default page or application behind several subdomains.
+type Cluster struct {
+	ID             int    // The number of the cluster, from 1, the largest first.
+	Size           int    // The number of responses in the cluster.
+	Representative string // The URL probed of the member with the shortest hostname.
 }
 
 // ResultType defines the type of result using an integer type. It can represent different
@@ -15,6 +74,42 @@ type ResultType int
 
 // Types of results returned by the source.
 const (
-	ResultSubdomain ResultType = iota // Represents a successful result containing a URL.
-	ResultError                       // Represents a result where an error occurred during the operation.
+	ResultSubdomain   ResultType = iota // Represents a successful result containing a URL.
+	ResultError                         // Represents a result where an error occurred during the operation.
+	ResultRelated                       // Represents a hostname related to the target, e.g. its mail provider, but outside its scope.
+	ResultRootDomain                    // Represents a root domain registered by the target's owner, a sibling of the target.
+	ResultIP                            // Represents an IP address a subdomain was found to resolve to.
+	ResultInfo                          // Represents an informational event, e.g. the progress of a source.
+	ResultCNAME                         // Represents a canonical name a subdomain, or a name of its CNAME chain, points to.
+	ResultTakeover                      // Represents a subdomain likely open to takeover, its evidence names the fingerprint matched.
+	ResultProbe                         // Represents a web server of a subdomain, its value is the URL probed.
+	ResultAttribution                   // Represents a subdomain found again, by another source than the one it was first found by.
 )
+
+// String returns the name of the result type, as used in machine readable output.
+func (t ResultType) String() string {
+	switch t {
+	case ResultSubdomain:
+		return "subdomain"
+	case ResultError:
+		return "error"
+	case ResultRelated:
+		return "related"
+	case ResultRootDomain:
+		return "root_domain"
+	case ResultIP:
+		return "ip"
+	case ResultInfo:
+		return "info"
+	case ResultCNAME:
+		return "cname"
+	case ResultTakeover:
+		return "takeover"
+	case ResultProbe:
+		return "probe"
+	case ResultAttribution:
+		return "attribution"
+	}
+
+	return "unknown"
+}
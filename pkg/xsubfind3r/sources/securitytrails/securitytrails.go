@@ -2,6 +2,7 @@ package securitytrails
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -24,7 +25,7 @@ type getSubdomainsResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -43,7 +44,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 			return
 		}
 
-		var scrollID string
+		scrollID := config.Cursor(domain, source.Name())
 
 		getSubdomainsReqHeaders := map[string]string{
 			"Content-Type": "application/json",
@@ -83,17 +84,17 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 
 				getSubdomainsReqBodyDataReader := bytes.NewReader(getSubdomainsReqBodyDataBytes)
 
-				getSubdomainsRes, err = httpclient.Post(getSubdomainsReqURL, "", getSubdomainsReqHeaders, getSubdomainsReqBodyDataReader)
+				getSubdomainsRes, err = httpclient.Post(ctx, getSubdomainsReqURL, "", getSubdomainsReqHeaders, getSubdomainsReqBodyDataReader)
 			} else {
 				getSubdomainsReqURL := fmt.Sprintf("https://api.securitytrails.com/v1/scroll/%s", scrollID)
 
-				getSubdomainsRes, err = httpclient.Get(getSubdomainsReqURL, "", getSubdomainsReqHeaders)
+				getSubdomainsRes, err = httpclient.Get(ctx, getSubdomainsReqURL, "", getSubdomainsReqHeaders)
 			}
 
-			if err != nil && getSubdomainsRes.StatusCode == status.Forbidden {
+			if err != nil && getSubdomainsRes != nil && getSubdomainsRes.StatusCode == status.Forbidden {
 				getSubdomainsReqURL := fmt.Sprintf("https://api.securitytrails.com/v1/domain/%s/subdomains?children_only=false&include_inactive=true", domain)
 
-				getSubdomainsRes, err = httpclient.Get(getSubdomainsReqURL, "", getSubdomainsReqHeaders)
+				getSubdomainsRes, err = httpclient.Get(ctx, getSubdomainsReqURL, "", getSubdomainsReqHeaders)
 			}
 
 			if err != nil {
@@ -159,6 +160,8 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 			if scrollID == "" {
 				break
 			}
+
+			config.SetCursor(domain, source.Name(), scrollID)
 		}
 	}()
 
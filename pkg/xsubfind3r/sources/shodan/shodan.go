@@ -1,6 +1,7 @@
 package shodan
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,13 +13,18 @@ import (
 type getDNSResponse struct {
 	Domain     string   `json:"domain"`
 	Subdomains []string `json:"subdomains"`
-	Result     int      `json:"result"`
-	Error      string   `json:"error"`
+	Data       []struct {
+		Subdomain string `json:"subdomain"`
+		Type      string `json:"type"`
+		Value     string `json:"value"`
+	} `json:"data"`
+	Result int    `json:"result"`
+	Error  string `json:"error"`
 }
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -41,7 +47,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 
 		var getDNSRes *http.Response
 
-		getDNSRes, err = httpclient.SimpleGet(getDNSReqURL)
+		getDNSRes, err = httpclient.SimpleGet(ctx, getDNSReqURL)
 		if err != nil {
 			result := sources.Result{
 				Type:   sources.ResultError,
@@ -83,6 +89,28 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 
 			results <- result
 		}
+
+		// A and AAAA records tell the addresses subdomains were seen at.
+		for _, record := range getDNSResData.Data {
+			if record.Type != "A" && record.Type != "AAAA" {
+				continue
+			}
+
+			subdomain := domain
+
+			if record.Subdomain != "" {
+				subdomain = record.Subdomain + "." + domain
+			}
+
+			result := sources.Result{
+				Type:      sources.ResultIP,
+				Source:    source.Name(),
+				Value:     record.Value,
+				Subdomain: subdomain,
+			}
+
+			results <- result
+		}
 	}()
 
 	return results
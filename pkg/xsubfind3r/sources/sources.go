@@ -1,5 +1,7 @@
 package sources
 
+import "context"
+
 // Source is an interface that defines methods for a data source.
 // Any source that implements this interface should define a process to run
 // data collection or scanning based on a configuration and domain,
@@ -9,13 +11,28 @@ type Source interface {
 	// It takes in a Configuration and a domain string as input and returns a channel
 	// of Result structs, which will asynchronously emit results from the data source.
 	// The use of channels allows for concurrent processing and retrieval of data.
-	Run(cfg *Configuration, domain string) <-chan Result
+	// Once ctx is canceled, in-flight requests are aborted and the channel is closed.
+	Run(ctx context.Context, cfg *Configuration, domain string) <-chan Result
 
 	// Name returns the name of the source. This can be used to identify the data source
 	// implementing the interface. Useful for logging, reporting, or debugging purposes.
 	Name() string
 }
 
+// Stage is an interface for a data source that builds on the subdomains found
+// by the sources, e.g. by generating and resolving variations of them. Stages
+// run once all sources have returned, and their results go through the same
+// normalization, deduplication and filtering as those of the sources.
+type Stage interface {
+	// Run starts the stage for a specific domain, given the subdomains found
+	// for it so far. It returns a channel of Result structs, closed once the
+	// stage is done or ctx is canceled.
+	Run(ctx context.Context, cfg *Configuration, domain string, subdomains []string) <-chan Result
+
+	// Name returns the name of the stage.
+	Name() string
+}
+
 // Constants representing the names of different data sources.
 // These constants can be used to refer to various OSINT (Open-Source Intelligence)
 // sources, threat intelligence platforms, or search engines that are commonly used
@@ -23,25 +40,44 @@ type Source interface {
 const (
 	ANUBIS             = "anubis"             // Anubis is an OSINT tool for gathering domain information.
 	BEVIGIL            = "bevigil"            // Bevigil is an OSINT platform focused on vulnerabilities in mobile apps.
+	BRUTEFORCE         = "bruteforce"         // Bruteforce resolves names built from a wordlist, it is active and opt-in.
 	BUILTWITH          = "builtwith"          // BuiltWith is a service for analyzing website technologies.
 	CENSYS             = "censys"             // Censys is a search engine for internet-connected devices and their data.
 	CERTIFICATEDETAILS = "certificatedetails" // CertificateDetails provides SSL/TLS certificate information.
 	CERTSPOTTER        = "certspotter"        // CertSpotter monitors SSL/TLS certificates for domains.
 	CHAOS              = "chaos"              // Chaos by ProjectDiscovery is a source for subdomain enumeration.
+	CIRCL              = "circl"              // CIRCL Passive DNS provides historical DNS records.
 	COMMONCRAWL        = "commoncrawl"        // Common Crawl is an open repository of web data.
+	CRAWL              = "crawl"              // Crawl fetches pages, headers and scripts of found hosts, it is an active and opt-in stage.
 	CRTSH              = "crtsh"              // crt.sh is a certificate transparency log search engine.
+	CTLOG              = "ctlog"              // CTLog reads certificate transparency logs directly over the RFC 6962 API.
+	DNSDB              = "dnsdb"              // Farsight DNSDB is a passive DNS database, searched with its Flexible Search API.
+	DNSRECORDS         = "dnsrecords"         // DNSRecords mines MX, NS, SPF, DMARC, SRV, TXT and CNAME records, it is an opt-in stage.
+	FOFA               = "fofa"               // FOFA is a search engine for internet-connected devices and services.
 	FULLHUNT           = "fullhunt"           // FullHunt is a platform for attack surface monitoring.
 	GITHUB             = "github"             // GitHub is a source for finding code repositories and related metadata.
+	GITLAB             = "gitlab"             // GitLab is a source for searching code on gitlab.com or self-hosted instances.
 	HACKERTARGET       = "hackertarget"       // HackerTarget provides security scanning services.
+	HUNTER             = "hunter"             // Hunter.how is a search engine for internet-connected devices and services.
+	IMPORT             = "import"             // Import reads hosts from the output files of other tools, e.g. HAR, Burp Suite, Nmap or Amass.
 	INTELLIGENCEX      = "intelx"             // Intelligence X is a search engine for intelligence gathering.
 	LEAKIX             = "leakix"             // LeakIX is a search engine for finding leaked and exposed data.
+	LOCALFS            = "localfs"            // LocalFS searches local files, directories and git repositories, and their history.
+	MNEMONIC           = "mnemonic"           // Mnemonic PassiveDNS provides historical DNS records.
+	NETLAS             = "netlas"             // Netlas is a search engine for internet-connected devices and their DNS and WHOIS data.
 	OPENTHREATEXCHANGE = "otx"                // Open Threat Exchange (OTX) is a collaborative threat intelligence platform.
+	PERMUTATION        = "permutation"        // Permutation resolves variations of found subdomains, it is an active and opt-in stage.
 	SECURITYTRAILS     = "securitytrails"     // SecurityTrails offers a comprehensive API for domain information.
 	SHODAN             = "shodan"             // Shodan is a search engine for internet-connected devices and vulnerabilities.
 	SUBDOMAINCENTER    = "subdomaincenter"    // SubdomainCenter is a tool for subdomain enumeration.
+	TAKEOVER           = "takeover"           // Takeover matches the CNAMEs of found hosts against dangling service fingerprints, it is an active and opt-in stage.
+	TLSGRAB            = "tlsgrab"            // TLSGrab harvests names from the certificates served by found hosts, it is an active and opt-in stage.
 	URLSCAN            = "urlscan"            // URLScan.io is a service for scanning websites and collecting URLs.
 	WAYBACK            = "wayback"            // Wayback Machine is an internet archive for historical website snapshots.
+	WHOIS              = "whois"              // WHOIS pivots on RDAP and reverse WHOIS data to find sibling root domains, it is an opt-in stage.
 	VIRUSTOTAL         = "virustotal"         // VirusTotal is a platform for scanning files and URLs for malware.
+	ZONEWALK           = "zonewalk"           // ZoneWalk tries zone transfers and walks DNSSEC chains of the target's nameservers, it is active and opt-in.
+	ZOOMEYE            = "zoomeye"            // ZoomEye is a search engine for internet-connected devices and domains.
 )
 
 // List contains a collection of all available source names.
@@ -55,13 +91,23 @@ var List = []string{
 	CERTIFICATEDETAILS,
 	CERTSPOTTER,
 	CHAOS,
+	CIRCL,
 	COMMONCRAWL,
 	CRTSH,
+	CTLOG,
+	DNSDB,
+	FOFA,
 	FULLHUNT,
 	GITHUB,
+	GITLAB,
 	HACKERTARGET,
+	HUNTER,
+	IMPORT,
 	INTELLIGENCEX,
 	LEAKIX,
+	LOCALFS,
+	MNEMONIC,
+	NETLAS,
 	OPENTHREATEXCHANGE,
 	SECURITYTRAILS,
 	SHODAN,
@@ -69,4 +115,20 @@ var List = []string{
 	URLSCAN,
 	WAYBACK,
 	VIRUSTOTAL,
+	ZOOMEYE,
+}
+
+// OptIn contains the names of the sources that are never used by default,
+// because they are active, i.e. they send traffic towards the target's
+// infrastructure, or look beyond the target domain. They run only when
+// explicitly requested, which keeps default runs passive and in scope.
+var OptIn = []string{
+	BRUTEFORCE,
+	CRAWL,
+	DNSRECORDS,
+	PERMUTATION,
+	TAKEOVER,
+	TLSGRAB,
+	WHOIS,
+	ZONEWALK,
 }
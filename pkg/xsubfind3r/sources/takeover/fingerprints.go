@@ -0,0 +1,75 @@
+package takeover
+
+import (
+	_ "embed"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fingerprint describes a service whose dangling CNAMEs can be claimed by
+// others, and how to tell a dangling one.
+type Fingerprint struct {
+	// Service is the name of the service, e.g. "GitHub Pages".
+	Service string `yaml:"service"`
+	// CNAME are the fragments of the canonical names of the service, e.g. ".github.io".
+	CNAME []string `yaml:"cname"`
+	// NXDomain, if true, tells the CNAME dangles when its chain ends with NXDOMAIN.
+	NXDomain bool `yaml:"nxdomain"`
+	// Body are the fragments of the page the service serves for unclaimed names.
+	Body []string `yaml:"body"`
+	// Status, if set, is the status code of that page.
+	Status int `yaml:"status"`
+}
+
+// builtin are the fingerprints used when no fingerprints file is given.
+//
+//go:embed fingerprints.yaml
+var builtin []byte
+
+// ReadFingerprints reads the fingerprints of the YAML file at path, or the
+// built-in ones if path is empty.
+func ReadFingerprints(path string) (fingerprints []Fingerprint, err error) {
+	data := builtin
+
+	if path != "" {
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return
+		}
+	}
+
+	err = yaml.Unmarshal(data, &fingerprints)
+
+	return
+}
+
+// matches reports whether name is a canonical name of the service.
+func (fingerprint *Fingerprint) matches(name string) bool {
+	for _, fragment := range fingerprint.CNAME {
+		if fragment != "" && strings.Contains(name, strings.ToLower(fragment)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// body returns the fragment of body matched, if any, for a page served with
+// status.
+func (fingerprint *Fingerprint) body(status int, body string) (fragment string, ok bool) {
+	if fingerprint.Status != 0 && fingerprint.Status != status {
+		return
+	}
+
+	for _, fragment = range fingerprint.Body {
+		if fragment != "" && strings.Contains(body, fragment) {
+			ok = true
+
+			return
+		}
+	}
+
+	return "", false
+}
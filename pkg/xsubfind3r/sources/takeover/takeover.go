@@ -0,0 +1,264 @@
+package takeover
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/internal/configuration"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+type Stage struct{}
+
+const (
+	defaultMaxHosts    = 1000
+	defaultConcurrency = 25
+	defaultTimeout     = 10 * time.Second
+
+	// maxBodySize caps the number of bytes of pages searched for fingerprints.
+	maxBodySize = 1 << 20
+)
+
+var ErrNoResolver = errors.New("no resolver configured")
+
+func (stage *Stage) Run(ctx context.Context, cfg *sources.Configuration, domain string, subdomains []string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		if cfg.Resolver == nil {
+			stage.error(ErrNoResolver, results)
+
+			return
+		}
+
+		fingerprints, err := ReadFingerprints(cfg.Takeover.Fingerprints)
+		if err != nil {
+			stage.error(err, results)
+
+			return
+		}
+
+		maxHosts := cfg.Takeover.MaxHosts
+
+		if maxHosts < 1 {
+			maxHosts = defaultMaxHosts
+		}
+
+		concurrency := cfg.Takeover.Concurrency
+
+		if concurrency < 1 {
+			concurrency = defaultConcurrency
+		}
+
+		timeout := defaultTimeout
+
+		if cfg.Takeover.Timeout != "" {
+			timeout, err = time.ParseDuration(cfg.Takeover.Timeout)
+			if err != nil {
+				stage.error(err, results)
+
+				return
+			}
+		}
+
+		c := &checker{
+			cfg:          cfg,
+			stage:        stage,
+			fingerprints: fingerprints,
+			client: &http.Client{
+				Timeout: timeout,
+				Transport: &http.Transport{
+					Proxy: http.ProxyFromEnvironment,
+					TLSClientConfig: &tls.Config{
+						InsecureSkipVerify: true, //nolint:gosec // Unclaimed names are served with certificates for others.
+						MinVersion:         tls.VersionTLS10,
+					},
+				},
+				// The page of an unclaimed name is served as is, not redirected to.
+				CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+					return http.ErrUseLastResponse
+				},
+			},
+			results: results,
+		}
+
+		hosts := []string{domain}
+
+		for _, subdomain := range subdomains {
+			if len(hosts) >= maxHosts {
+				break
+			}
+
+			if subdomain != domain {
+				hosts = append(hosts, subdomain)
+			}
+		}
+
+		queue := make(chan string)
+
+		wg := &sync.WaitGroup{}
+
+		for range concurrency {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for host := range queue {
+					c.host(ctx, host)
+				}
+			}()
+		}
+
+	feed:
+		for _, host := range hosts {
+			select {
+			case <-ctx.Done():
+				break feed
+			case queue <- host:
+			}
+		}
+
+		close(queue)
+
+		wg.Wait()
+	}()
+
+	return results
+}
+
+func (stage *Stage) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: stage.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (stage *Stage) Name() string {
+	return sources.TAKEOVER
+}
+
+type checker struct {
+	cfg          *sources.Configuration
+	stage        *Stage
+	fingerprints []Fingerprint
+	client       *http.Client
+
+	results chan sources.Result
+}
+
+// host reports the CNAME chain of host and, if a name of the chain matches a
+// fingerprint that tells it dangles, reports host as a takeover candidate.
+func (c *checker) host(ctx context.Context, host string) {
+	chain, rcode, err := c.cfg.Resolver.LookupCNAME(ctx, host)
+	if err != nil || len(chain) == 0 {
+		return
+	}
+
+	owner := host
+
+	for _, target := range chain {
+		result := sources.Result{
+			Type:      sources.ResultCNAME,
+			Source:    c.stage.Name(),
+			Value:     target,
+			Subdomain: owner,
+		}
+
+		c.results <- result
+
+		owner = target
+	}
+
+	target := chain[len(chain)-1]
+
+	for index := range c.fingerprints {
+		fingerprint := &c.fingerprints[index]
+
+		if !matchesChain(fingerprint, chain) {
+			continue
+		}
+
+		evidence := ""
+
+		switch {
+		case fingerprint.NXDomain && rcode == dnsmessage.RCodeNameError:
+			evidence = fmt.Sprintf("%s: CNAME %s, NXDOMAIN", fingerprint.Service, target)
+		case len(fingerprint.Body) > 0:
+			if fragment, ok := c.page(ctx, host, fingerprint); ok {
+				evidence = fmt.Sprintf("%s: CNAME %s, page matches %q", fingerprint.Service, target, fragment)
+			}
+		}
+
+		if evidence == "" {
+			continue
+		}
+
+		result := sources.Result{
+			Type:     sources.ResultTakeover,
+			Source:   c.stage.Name(),
+			Value:    host,
+			Evidence: evidence,
+		}
+
+		c.results <- result
+
+		return
+	}
+}
+
+// page fetches the page host serves, over HTTPS then HTTP, and returns the
+// fragment of fingerprint it contains, if any.
+func (c *checker) page(ctx context.Context, host string, fingerprint *Fingerprint) (fragment string, ok bool) {
+	for _, scheme := range []string{"https", "http"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+host+"/", http.NoBody)
+		if err != nil {
+			return
+		}
+
+		req.Header.Set("User-Agent", fmt.Sprintf("%s v%s (https://github.com/hueristiq/%s)", configuration.NAME, configuration.VERSION, configuration.NAME))
+
+		res, err := c.client.Do(req)
+		if err != nil {
+			continue
+		}
+
+		body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+
+		res.Body.Close()
+
+		if err != nil {
+			continue
+		}
+
+		if fragment, ok = fingerprint.body(res.StatusCode, string(body)); ok {
+			return
+		}
+	}
+
+	return
+}
+
+// matchesChain reports whether a name of chain is a canonical name of the
+// service of fingerprint.
+func matchesChain(fingerprint *Fingerprint, chain []string) bool {
+	for _, name := range chain {
+		if fingerprint.matches(name) {
+			return true
+		}
+	}
+
+	return false
+}
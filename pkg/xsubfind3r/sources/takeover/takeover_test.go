@@ -0,0 +1,219 @@
+package takeover
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsStub is a local DNS server answering A queries with the CNAME chains of
+// cnames. Names in addresses resolve to 192.0.2.1, others do not exist.
+type dnsStub struct {
+	conn net.PacketConn
+
+	cnames    map[string]string
+	addresses map[string]bool
+}
+
+func newDNSStub(t *testing.T, cnames map[string]string, addresses ...string) (stub *dnsStub) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub = &dnsStub{
+		conn:      conn,
+		cnames:    cnames,
+		addresses: map[string]bool{},
+	}
+
+	for _, address := range addresses {
+		stub.addresses[address] = true
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	go stub.serve()
+
+	return
+}
+
+func (stub *dnsStub) serve() {
+	buffer := make([]byte, 65535)
+
+	for {
+		n, address, err := stub.conn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+
+		var query dnsmessage.Message
+
+		if err = query.Unpack(buffer[:n]); err != nil || len(query.Questions) != 1 {
+			continue
+		}
+
+		answer := stub.answer(query)
+
+		response, err := answer.Pack()
+		if err != nil {
+			continue
+		}
+
+		stub.conn.WriteTo(response, address)
+	}
+}
+
+func (stub *dnsStub) answer(query dnsmessage.Message) (response dnsmessage.Message) {
+	response.Header = dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true}
+	response.Questions = query.Questions
+
+	name := strings.TrimSuffix(strings.ToLower(query.Questions[0].Name.String()), ".")
+
+	for range 10 {
+		target, ok := stub.cnames[name]
+		if !ok {
+			break
+		}
+
+		response.Answers = append(response.Answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name + "."), Class: dnsmessage.ClassINET, TTL: 60},
+			Body:   &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target + ".")},
+		})
+
+		name = target
+	}
+
+	switch {
+	case !stub.addresses[name]:
+		response.RCode = dnsmessage.RCodeNameError
+	case query.Questions[0].Type == dnsmessage.TypeA:
+		response.Answers = append(response.Answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name + "."), Class: dnsmessage.ClassINET, TTL: 60},
+			Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+		})
+	}
+
+	return
+}
+
+// check runs the checker on host, with the built-in fingerprints, resolving
+// names with stub and fetching pages from pages, whatever the host. It returns
+// the evidence of the takeover reported, if any.
+func check(t *testing.T, stub *dnsStub, pages http.Handler, host string) (evidence string, reported bool) {
+	t.Helper()
+
+	fingerprints, err := ReadFingerprints("")
+	if err != nil {
+		t.Fatalf("ReadFingerprints() error = %v", err)
+	}
+
+	server := httptest.NewServer(pages)
+
+	t.Cleanup(server.Close)
+
+	results := make(chan sources.Result)
+
+	c := &checker{
+		cfg: &sources.Configuration{
+			Resolver: resolver.New(&resolver.Configuration{
+				Servers: []string{stub.conn.LocalAddr().String()},
+				Timeout: time.Second,
+			}),
+		},
+		stage:        &Stage{},
+		fingerprints: fingerprints,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+				},
+			},
+		},
+		results: results,
+	}
+
+	go func() {
+		defer close(results)
+
+		c.host(context.Background(), host)
+	}()
+
+	for result := range results {
+		if result.Type == sources.ResultTakeover {
+			evidence, reported = result.Evidence, true
+		}
+	}
+
+	return
+}
+
+func TestTakeoverBody(t *testing.T) {
+	stub := newDNSStub(t, map[string]string{
+		"docs.example.com": "example.github.io",
+		"blog.example.com": "claimed.github.io",
+	}, "example.github.io", "claimed.github.io")
+
+	pages := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host == "docs.example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<h1>404</h1><p>There isn't a GitHub Pages site here.</p>"))
+
+			return
+		}
+
+		w.Write([]byte("<h1>Blog</h1>"))
+	})
+
+	evidence, reported := check(t, stub, pages, "docs.example.com")
+	if !reported {
+		t.Fatal("docs.example.com not reported")
+	}
+
+	if !strings.HasPrefix(evidence, "GitHub Pages: CNAME example.github.io") {
+		t.Errorf("evidence = %q, want the GitHub Pages fingerprint", evidence)
+	}
+
+	if _, reported = check(t, stub, pages, "blog.example.com"); reported {
+		t.Error("blog.example.com, whose page is served, reported")
+	}
+}
+
+func TestTakeoverNXDomain(t *testing.T) {
+	stub := newDNSStub(t, map[string]string{
+		"old.example.com":   "gone.elasticbeanstalk.com",
+		"app.example.com":   "live.elasticbeanstalk.com",
+		"other.example.com": "gone.example.net",
+	}, "live.elasticbeanstalk.com")
+
+	pages := http.NotFoundHandler()
+
+	evidence, reported := check(t, stub, pages, "old.example.com")
+	if !reported {
+		t.Fatal("old.example.com not reported")
+	}
+
+	if want := "AWS Elastic Beanstalk: CNAME gone.elasticbeanstalk.com, NXDOMAIN"; evidence != want {
+		t.Errorf("evidence = %q, want %q", evidence, want)
+	}
+
+	if _, reported = check(t, stub, pages, "app.example.com"); reported {
+		t.Error("app.example.com, whose CNAME resolves, reported")
+	}
+
+	if _, reported = check(t, stub, pages, "other.example.com"); reported {
+		t.Error("other.example.com, whose CNAME matches no fingerprint, reported")
+	}
+}
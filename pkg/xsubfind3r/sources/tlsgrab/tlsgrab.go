@@ -0,0 +1,260 @@
+package tlsgrab
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type Stage struct{}
+
+// target is an endpoint to handshake with, with the SNI name to send, if any.
+type target struct {
+	address string
+	sni     string
+}
+
+const (
+	defaultMaxHosts    = 1000
+	defaultConcurrency = 25
+	defaultTimeout     = 5 * time.Second
+)
+
+var (
+	// defaultPorts are used when no ports are configured.
+	defaultPorts = []int{443, 8443}
+
+	ErrNoResolver    = errors.New("no resolver configured")
+	ErrNoCertificate = errors.New("no certificate presented")
+)
+
+func (stage *Stage) Run(ctx context.Context, cfg *sources.Configuration, domain string, subdomains []string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		if cfg.Resolver == nil {
+			stage.error(ErrNoResolver, results)
+
+			return
+		}
+
+		ports := cfg.TLSGrab.Ports
+
+		if len(ports) == 0 {
+			ports = defaultPorts
+		}
+
+		maxHosts := cfg.TLSGrab.MaxHosts
+
+		if maxHosts < 1 {
+			maxHosts = defaultMaxHosts
+		}
+
+		concurrency := cfg.TLSGrab.Concurrency
+
+		if concurrency < 1 {
+			concurrency = defaultConcurrency
+		}
+
+		timeout := defaultTimeout
+
+		if cfg.TLSGrab.Timeout != "" {
+			var err error
+
+			timeout, err = time.ParseDuration(cfg.TLSGrab.Timeout)
+			if err != nil {
+				stage.error(err, results)
+
+				return
+			}
+		}
+
+		hosts := []string{domain}
+
+		for _, subdomain := range subdomains {
+			if len(hosts) >= maxHosts {
+				break
+			}
+
+			if subdomain != domain {
+				hosts = append(hosts, subdomain)
+			}
+		}
+
+		// Resolve hosts first: handshakes with SNI go to the first address of
+		// each host, handshakes without SNI go once to each distinct address.
+		addresses := make([]string, len(hosts))
+
+		parallel(ctx, concurrency, len(hosts), func(index int) {
+			resolved, err := cfg.Resolver.LookupHost(ctx, hosts[index])
+			if err != nil || len(resolved) == 0 {
+				return
+			}
+
+			addresses[index] = resolved[0]
+		})
+
+		var targets []target
+
+		distinct := map[string]struct{}{}
+
+		for index, host := range hosts {
+			address := addresses[index]
+
+			if address == "" {
+				continue
+			}
+
+			for _, port := range ports {
+				endpoint := net.JoinHostPort(address, strconv.Itoa(port))
+
+				targets = append(targets, target{address: endpoint, sni: host})
+
+				if _, ok := distinct[endpoint]; !ok {
+					distinct[endpoint] = struct{}{}
+
+					targets = append(targets, target{address: endpoint})
+				}
+			}
+		}
+
+		seen := &sync.Map{}
+
+		parallel(ctx, concurrency, len(targets), func(index int) {
+			t := targets[index]
+
+			certificate, err := handshake(ctx, t, timeout)
+			if err != nil {
+				return
+			}
+
+			fingerprint := sha256.Sum256(certificate.Raw)
+
+			evidence := fmt.Sprintf("sha256:%s @ %s", hex.EncodeToString(fingerprint[:]), t.address)
+
+			for _, name := range sources.CertificateNames(certificate) {
+				if _, loaded := seen.LoadOrStore(name, struct{}{}); loaded {
+					continue
+				}
+
+				result := sources.Result{
+					Type:     sources.ResultSubdomain,
+					Source:   stage.Name(),
+					Value:    name,
+					Evidence: evidence,
+				}
+
+				if name != domain && !strings.HasSuffix(name, "."+domain) {
+					result.Type = sources.ResultRelated
+				}
+
+				results <- result
+			}
+		})
+	}()
+
+	return results
+}
+
+func (stage *Stage) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: stage.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (stage *Stage) Name() string {
+	return sources.TLSGRAB
+}
+
+// handshake completes a TLS handshake with t and returns the leaf certificate.
+// Certificates are not verified: self-signed and private CA certificates are
+// precisely those that reveal names not found elsewhere.
+func handshake(ctx context.Context, t target, timeout time.Duration) (certificate *x509.Certificate, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	defer cancel()
+
+	dialer := &net.Dialer{}
+
+	var conn net.Conn
+
+	conn, err = dialer.DialContext(ctx, "tcp", t.address)
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	// An empty ServerName sends no SNI, the server answers with its default certificate.
+	client := tls.Client(conn, &tls.Config{
+		ServerName:         t.sni,
+		InsecureSkipVerify: true, //nolint:gosec // See above.
+		MinVersion:         tls.VersionTLS10,
+	})
+
+	if err = client.HandshakeContext(ctx); err != nil {
+		return
+	}
+
+	certificates := client.ConnectionState().PeerCertificates
+
+	if len(certificates) == 0 {
+		err = ErrNoCertificate
+
+		return
+	}
+
+	certificate = certificates[0]
+
+	return
+}
+
+// parallel calls work for every index in [0, n), with at most concurrency
+// calls at a time, and returns once all calls returned or ctx is canceled.
+func parallel(ctx context.Context, concurrency, n int, work func(index int)) {
+	indexes := make(chan int)
+
+	wg := &sync.WaitGroup{}
+
+	for range concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				work(index)
+			}
+		}()
+	}
+
+feed:
+	for index := range n {
+		select {
+		case <-ctx.Done():
+			break feed
+		case indexes <- index:
+		}
+	}
+
+	close(indexes)
+
+	wg.Wait()
+}
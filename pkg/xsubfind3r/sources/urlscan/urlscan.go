@@ -1,6 +1,7 @@
 package urlscan
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,6 +17,7 @@ type searchResponse struct {
 	Results []struct {
 		Page struct {
 			Domain   string `json:"domain"`
+			IP       string `json:"ip"`
 			MimeType string `json:"mimeType"`
 			URL      string `json:"url"`
 			Status   string `json:"status"`
@@ -30,7 +32,7 @@ type searchResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -57,7 +59,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 			searchReqHeaders["API-Key"] = key
 		}
 
-		var after string
+		after := config.Cursor(domain, source.Name())
 
 		for {
 			searchReqURL := fmt.Sprintf("https://urlscan.io/api/v1/search/?q=domain:%s&size=10000", domain)
@@ -68,7 +70,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 
 			var searchRes *http.Response
 
-			searchRes, err = httpclient.Get(searchReqURL, "", searchReqHeaders)
+			searchRes, err = httpclient.Get(ctx, searchReqURL, "", searchReqHeaders)
 			if err != nil {
 				result := sources.Result{
 					Type:   sources.ResultError,
@@ -108,7 +110,17 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 			for _, record := range searchResData.Results {
 				subdomain := record.Page.Domain
 
-				if subdomain != domain && !strings.HasSuffix(subdomain, "."+domain) {
+				// Pages under other roots loaded resources from the target, they are related to it.
+				if !sources.InScope(subdomain, domain) {
+					result := sources.Result{
+						Type:     sources.ResultRelated,
+						Source:   source.Name(),
+						Value:    subdomain,
+						Evidence: record.Page.URL,
+					}
+
+					results <- result
+
 					continue
 				}
 
@@ -119,6 +131,17 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 				}
 
 				results <- result
+
+				if record.Page.IP != "" {
+					result := sources.Result{
+						Type:      sources.ResultIP,
+						Source:    source.Name(),
+						Value:     record.Page.IP,
+						Subdomain: subdomain,
+					}
+
+					results <- result
+				}
 			}
 
 			if !searchResData.HasMore {
@@ -139,6 +162,8 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 				}
 
 				after = strings.Join(temp, ",")
+
+				config.SetCursor(domain, source.Name(), after)
 			}
 		}
 	}()
@@ -1,6 +1,7 @@
 package virustotal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -19,7 +20,7 @@ type getSubdomainsResponse struct {
 
 type Source struct{}
 
-func (source *Source) Run(config *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, config *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -51,7 +52,7 @@ func (source *Source) Run(config *sources.Configuration, domain string) <-chan s
 				getSubdomainsReqURL = fmt.Sprintf("%s&cursor=%s", getSubdomainsReqURL, cursor)
 			}
 
-			getSubdomainsRes, err := httpclient.Get(getSubdomainsReqURL, "", getSubdomainsReqHeaders)
+			getSubdomainsRes, err := httpclient.Get(ctx, getSubdomainsReqURL, "", getSubdomainsReqHeaders)
 			if err != nil {
 				result := sources.Result{
 					Type:   sources.ResultError,
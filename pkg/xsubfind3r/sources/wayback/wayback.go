@@ -1,6 +1,7 @@
 package wayback
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	hqgolimiter "github.com/hueristiq/hq-go-limiter"
 	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"github.com/spf13/cast"
 )
 
 type Source struct{}
@@ -16,7 +18,7 @@ var limiter = hqgolimiter.New(&hqgolimiter.Configuration{
 	RequestsPerMinute: 40,
 })
 
-func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sources.Result {
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
 	results := make(chan sources.Result)
 
 	go func() {
@@ -24,14 +26,14 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 
 		var err error
 
-		for page := uint(0); ; page++ {
+		for page := cast.ToUint(cfg.Cursor(domain, source.Name())); ; page++ {
 			limiter.Wait()
 
 			getURLsReqURL := fmt.Sprintf("https://web.archive.org/cdx/search/cdx?url=*.%s/*&output=json&collapse=urlkey&fl=original&pageSize=100&page=%d", domain, page)
 
 			var getURLsRes *http.Response
 
-			getURLsRes, err = httpclient.SimpleGet(getURLsReqURL)
+			getURLsRes, err = httpclient.SimpleGet(ctx, getURLsReqURL)
 			if err != nil {
 				result := sources.Result{
 					Type:   sources.ResultError,
@@ -83,7 +85,25 @@ func (source *Source) Run(cfg *sources.Configuration, domain string) <-chan sour
 
 					results <- result
 				}
+
+				// Archived URLs reference other hosts, e.g. redirect targets in their query.
+				for _, host := range sources.URLHosts(entry[0]) {
+					if sources.InScope(host, domain) {
+						continue
+					}
+
+					result := sources.Result{
+						Type:     sources.ResultRelated,
+						Source:   source.Name(),
+						Value:    host,
+						Evidence: entry[0],
+					}
+
+					results <- result
+				}
 			}
+
+			cfg.SetCursor(domain, source.Name(), cast.ToString(page+1))
 		}
 	}()
 
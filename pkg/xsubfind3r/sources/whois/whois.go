@@ -0,0 +1,414 @@
+package whois
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	hqgourl "github.com/hueristiq/hq-go-url"
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// rdapDomain holds the parts of an RDAP domain object (RFC 9083) used to pivot.
+type rdapDomain struct {
+	Entities    []rdapEntity `json:"entities"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+}
+
+type rdapEntity struct {
+	Roles []string `json:"roles"`
+	// VCardArray is a jCard (RFC 7095): `["vcard", [[name, params, type, value], ...]]`.
+	VCardArray []json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity      `json:"entities"`
+}
+
+type reverseWhoisRequestBody struct {
+	APIKey           string `json:"apiKey"`
+	SearchType       string `json:"searchType"`
+	Mode             string `json:"mode"`
+	Punycode         bool   `json:"punycode"`
+	BasicSearchTerms struct {
+		Include []string `json:"include"`
+	} `json:"basicSearchTerms"`
+}
+
+type reverseWhoisResponse struct {
+	DomainsCount int      `json:"domainsCount"`
+	DomainsList  []string `json:"domainsList"`
+}
+
+type reverseNSResponse struct {
+	Result []struct {
+		Name string `json:"name"`
+	} `json:"result"`
+}
+
+// registrant holds the registrant details of a domain searched for siblings.
+type registrant struct {
+	organization string
+	email        string
+}
+
+type Stage struct{}
+
+const defaultMaxDomains = 100
+
+var (
+	// dp splits the names of nameservers into their root domain and TLD.
+	dp = hqgourl.NewDomainParser()
+
+	// redactedMarkers are found in registrant details hidden for privacy,
+	// which would relate unrelated domains if searched.
+	redactedMarkers = []string{"redacted", "privacy", "withheld", "not disclosed", "data protected", "proxy"}
+)
+
+func (stage *Stage) Run(ctx context.Context, cfg *sources.Configuration, domain string, _ []string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		maxDomains := cfg.Whois.MaxDomains
+
+		if maxDomains < 1 {
+			maxDomains = defaultMaxDomains
+		}
+
+		p := &pivot{
+			cfg:        cfg,
+			stage:      stage,
+			domain:     domain,
+			maxDomains: maxDomains,
+			seen:       map[string]struct{}{domain: {}},
+			results:    results,
+		}
+
+		target, err := lookup(ctx, domain)
+		if err != nil {
+			stage.error(err, results)
+
+			return
+		}
+
+		owner := target.registrant()
+
+		// Vanity nameservers, under another root, are often registered by the
+		// same organization: that root is a sibling if its registrant matches.
+		for _, nameserver := range target.nameservers() {
+			root := rootDomain(nameserver)
+
+			if root == "" || root == domain {
+				continue
+			}
+
+			if _, ok := p.seen[root]; ok {
+				continue
+			}
+
+			related, err := lookup(ctx, root)
+			if err != nil {
+				continue
+			}
+
+			if owner.matches(related.registrant()) {
+				p.report(root, "same registrant as nameserver "+nameserver)
+			}
+		}
+
+		key, err := cfg.Keys.WhoisXML.PickRandom()
+		if key == "" || err != nil {
+			return
+		}
+
+		for _, term := range []string{owner.organization, owner.email} {
+			if term == "" || ctx.Err() != nil {
+				continue
+			}
+
+			p.reverseWhois(ctx, key, term)
+		}
+
+		// Domains served by the target's own nameservers belong to it too.
+		for _, nameserver := range target.nameservers() {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if strings.HasSuffix(nameserver, "."+domain) {
+				p.reverseNS(ctx, key, nameserver)
+			}
+		}
+	}()
+
+	return results
+}
+
+func (stage *Stage) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: stage.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (stage *Stage) Name() string {
+	return sources.WHOIS
+}
+
+type pivot struct {
+	cfg        *sources.Configuration
+	stage      *Stage
+	domain     string
+	maxDomains int
+
+	// seen holds the root domains already reported, and the target itself.
+	seen map[string]struct{}
+
+	results chan sources.Result
+}
+
+// report reports root as a root domain related to the target, with evidence,
+// unless already reported or the maximum number of domains was reached.
+func (p *pivot) report(root, evidence string) {
+	root = strings.TrimSuffix(strings.ToLower(root), ".")
+
+	if _, ok := p.seen[root]; ok || len(p.seen) > p.maxDomains {
+		return
+	}
+
+	p.seen[root] = struct{}{}
+
+	result := sources.Result{
+		Type:     sources.ResultRootDomain,
+		Source:   p.stage.Name(),
+		Value:    root,
+		Evidence: evidence,
+	}
+
+	p.results <- result
+}
+
+// reverseWhois reports the domains whose current WHOIS record holds term,
+// using the WhoisXML API Reverse WHOIS API.
+func (p *pivot) reverseWhois(ctx context.Context, key, term string) {
+	body := reverseWhoisRequestBody{
+		APIKey:     key,
+		SearchType: "current",
+		Mode:       "purchase",
+		Punycode:   true,
+	}
+
+	body.BasicSearchTerms.Include = []string{term}
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		p.stage.error(err, p.results)
+
+		return
+	}
+
+	reverseWhoisReqHeaders := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var reverseWhoisRes *http.Response
+
+	reverseWhoisRes, err = httpclient.Post(ctx, "https://reverse-whois.whoisxmlapi.com/api/v2", "", reverseWhoisReqHeaders, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		p.stage.error(err, p.results)
+
+		httpclient.DiscardResponse(reverseWhoisRes)
+
+		return
+	}
+
+	var reverseWhoisResData reverseWhoisResponse
+
+	if err = json.NewDecoder(reverseWhoisRes.Body).Decode(&reverseWhoisResData); err != nil {
+		p.stage.error(err, p.results)
+
+		reverseWhoisRes.Body.Close()
+
+		return
+	}
+
+	reverseWhoisRes.Body.Close()
+
+	for _, root := range reverseWhoisResData.DomainsList {
+		p.report(root, "registrant "+term)
+	}
+}
+
+// reverseNS reports the domains served by nameserver, using the WhoisXML API
+// Reverse NS API.
+func (p *pivot) reverseNS(ctx context.Context, key, nameserver string) {
+	reverseNSReqURL := fmt.Sprintf("https://reverse-ns.whoisxmlapi.com/api/v1?apiKey=%s&ns=%s", key, nameserver)
+
+	reverseNSRes, err := httpclient.SimpleGet(ctx, reverseNSReqURL)
+	if err != nil {
+		p.stage.error(err, p.results)
+
+		httpclient.DiscardResponse(reverseNSRes)
+
+		return
+	}
+
+	var reverseNSResData reverseNSResponse
+
+	if err = json.NewDecoder(reverseNSRes.Body).Decode(&reverseNSResData); err != nil {
+		p.stage.error(err, p.results)
+
+		reverseNSRes.Body.Close()
+
+		return
+	}
+
+	reverseNSRes.Body.Close()
+
+	for _, record := range reverseNSResData.Result {
+		p.report(record.Name, "nameserver "+nameserver)
+	}
+}
+
+// lookup fetches the RDAP record of domain through the rdap.org bootstrap
+// service, which redirects to the registry serving it.
+func lookup(ctx context.Context, domain string) (record *rdapDomain, err error) {
+	var res *http.Response
+
+	res, err = httpclient.Get(ctx, "https://rdap.org/domain/"+domain, "", map[string]string{
+		"Accept": "application/rdap+json",
+	})
+	if err != nil {
+		httpclient.DiscardResponse(res)
+
+		return
+	}
+
+	defer res.Body.Close()
+
+	record = &rdapDomain{}
+
+	err = json.NewDecoder(res.Body).Decode(record)
+
+	return
+}
+
+// nameservers returns the lowercased names of the nameservers of record.
+func (record *rdapDomain) nameservers() (nameservers []string) {
+	for _, nameserver := range record.Nameservers {
+		name := strings.TrimSuffix(strings.ToLower(nameserver.LDHName), ".")
+
+		if name != "" {
+			nameservers = append(nameservers, name)
+		}
+	}
+
+	return
+}
+
+// registrant returns the registrant details of record, leaving out those
+// redacted for privacy.
+func (record *rdapDomain) registrant() (r registrant) {
+	var walk func(entities []rdapEntity)
+
+	walk = func(entities []rdapEntity) {
+		for _, entity := range entities {
+			for _, role := range entity.Roles {
+				if role != "registrant" {
+					continue
+				}
+
+				properties := vcardProperties(entity.VCardArray)
+
+				if r.organization == "" && !redacted(properties["org"]) {
+					r.organization = properties["org"]
+				}
+
+				if r.email == "" && !redacted(properties["email"]) {
+					r.email = strings.ToLower(properties["email"])
+				}
+			}
+
+			walk(entity.Entities)
+		}
+	}
+
+	walk(record.Entities)
+
+	return
+}
+
+// matches reports whether r and other share their organization or email.
+func (r registrant) matches(other registrant) bool {
+	return (r.organization != "" && strings.EqualFold(r.organization, other.organization)) ||
+		(r.email != "" && r.email == other.email)
+}
+
+// vcardProperties returns the text values of the properties of a jCard,
+// keyed by property name.
+func vcardProperties(vcard []json.RawMessage) (properties map[string]string) {
+	properties = map[string]string{}
+
+	if len(vcard) != 2 {
+		return
+	}
+
+	var entries [][]json.RawMessage
+
+	if err := json.Unmarshal(vcard[1], &entries); err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if len(entry) < 4 {
+			continue
+		}
+
+		var name, value string
+
+		if json.Unmarshal(entry[0], &name) != nil || json.Unmarshal(entry[3], &value) != nil {
+			continue
+		}
+
+		properties[name] = strings.TrimSpace(value)
+	}
+
+	return
+}
+
+// redacted reports whether value is empty or hidden for privacy.
+func redacted(value string) bool {
+	if value == "" {
+		return true
+	}
+
+	lower := strings.ToLower(value)
+
+	for _, marker := range redactedMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rootDomain returns the root domain of name, e.g. "example.com" for
+// "ns1.example.com", or an empty string if it has none.
+func rootDomain(name string) string {
+	parsed := dp.Parse(name)
+
+	if parsed.Root == "" || parsed.TopLevel == "" {
+		return ""
+	}
+
+	return parsed.Root + "." + parsed.TopLevel
+}
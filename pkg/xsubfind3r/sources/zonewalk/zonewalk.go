@@ -0,0 +1,475 @@
+package zonewalk
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/wordlist"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+type Source struct{}
+
+const (
+	defaultMaxNames     = 10000
+	defaultNSEC3Queries = 200
+	// nsec3Patience is the number of queries in a row without a new hash
+	// after which NSEC3 collection stops early.
+	nsec3Patience = 25
+)
+
+var (
+	ErrNoResolver    = errors.New("no resolver configured")
+	ErrNoNameservers = errors.New("no nameservers found")
+	ErrNoNSEC        = errors.New("no NSEC or NSEC3 records served")
+	ErrSyntheticNSEC = errors.New("NSEC records are synthesized on the fly (black lies), the zone cannot be walked")
+)
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		if cfg.Resolver == nil {
+			source.error(ErrNoResolver, results)
+
+			return
+		}
+
+		nameservers := cfg.ZoneWalk.Nameservers
+
+		if len(nameservers) == 0 {
+			var err error
+
+			nameservers, err = source.nameservers(ctx, cfg.Resolver, domain)
+			if err != nil {
+				source.error(err, results)
+
+				return
+			}
+		}
+
+		// Transfers are mostly refused, their errors are only reported if the
+		// walk fails too.
+		var errs []error
+
+		// A successful transfer lists the whole zone, there is nothing left to walk.
+		for _, nameserver := range nameservers {
+			if ctx.Err() != nil {
+				return
+			}
+
+			records, err := cfg.Resolver.Transfer(ctx, nameserver, domain)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("AXFR %s: %w", nameserver, err))
+
+				continue
+			}
+
+			for _, record := range records {
+				for _, name := range recordNames(record) {
+					source.subdomain(name, results)
+				}
+			}
+
+			return
+		}
+
+		for _, nameserver := range nameservers {
+			if ctx.Err() != nil {
+				return
+			}
+
+			err := source.walk(ctx, cfg, nameserver, domain, results)
+			if err == nil {
+				return
+			}
+
+			errs = append(errs, fmt.Errorf("%s: %w", nameserver, err))
+		}
+
+		source.error(errors.Join(errs...), results)
+	}()
+
+	return results
+}
+
+// nameservers looks up the addresses of the authoritative nameservers of domain.
+func (source *Source) nameservers(ctx context.Context, r *resolver.Resolver, domain string) (nameservers []string, err error) {
+	var msg *dnsmessage.Message
+
+	msg, err = r.Query(ctx, domain, dnsmessage.TypeNS)
+	if err != nil {
+		return
+	}
+
+	for _, answer := range msg.Answers {
+		ns, ok := answer.Body.(*dnsmessage.NSResource)
+		if !ok {
+			continue
+		}
+
+		var addresses []string
+
+		addresses, err = r.LookupHost(ctx, ns.NS.String())
+		if err != nil {
+			return
+		}
+
+		nameservers = append(nameservers, addresses...)
+	}
+
+	if len(nameservers) == 0 {
+		err = ErrNoNameservers
+	}
+
+	return
+}
+
+// walk lists the names of domain from the DNSSEC denial of existence records
+// served by nameserver: by following the chain of NSEC records, or, for zones
+// signed with NSEC3, by collecting hashes and cracking them against a wordlist.
+func (source *Source) walk(ctx context.Context, cfg *sources.Configuration, nameserver, domain string, results chan sources.Result) (err error) {
+	apex := domain + "."
+
+	var msg *dnsmessage.Message
+
+	// NXDOMAIN answers prove non-existence with the NSEC or NSEC3 records
+	// covering the queried name, they tell which of the two the zone uses.
+	msg, err = query(ctx, cfg.Resolver, nameserver, resolver.RandomLabel()+"."+apex, dnsmessage.TypeA)
+	if err != nil {
+		return
+	}
+
+	for _, record := range msg.Authorities {
+		switch record.Header.Type {
+		case resolver.TypeNSEC:
+			if body, ok := record.Body.(*dnsmessage.UnknownResource); ok {
+				if next, parseErr := resolver.ParseNSEC(body.Data); parseErr == nil && synthetic(record.Header.Name.String(), next) {
+					err = ErrSyntheticNSEC
+
+					return
+				}
+			}
+
+			err = source.walkNSEC(ctx, cfg, nameserver, apex, results)
+
+			return
+		case resolver.TypeNSEC3:
+			err = source.collectNSEC3(ctx, cfg, nameserver, domain, results)
+
+			return
+		}
+	}
+
+	err = ErrNoNSEC
+
+	return
+}
+
+// walkNSEC follows the NSEC chain from the apex, each record naming the next
+// name of the zone, until it loops back to the apex. Chains synthesized on the
+// fly, whose records only cover the name queried, stop the walk with
+// ErrSyntheticNSEC.
+func (source *Source) walkNSEC(ctx context.Context, cfg *sources.Configuration, nameserver, apex string, results chan sources.Result) (err error) {
+	maxNames := cfg.ZoneWalk.MaxNames
+
+	if maxNames < 1 {
+		maxNames = defaultMaxNames
+	}
+
+	seen := map[string]struct{}{}
+
+	name := apex
+
+	for range maxNames {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var next string
+
+		next, err = nextNSEC(ctx, cfg.Resolver, nameserver, name)
+		if err != nil {
+			return
+		}
+
+		if synthetic(name, next) {
+			err = ErrSyntheticNSEC
+
+			return
+		}
+
+		next = strings.ToLower(next)
+
+		if _, ok := seen[next]; ok || next == apex || !strings.HasSuffix(next, "."+apex) {
+			return
+		}
+
+		seen[next] = struct{}{}
+
+		source.subdomain(next, results)
+
+		name = next
+	}
+
+	return
+}
+
+// nextNSEC returns the next owner name of the NSEC record owned by name.
+func nextNSEC(ctx context.Context, r *resolver.Resolver, nameserver, name string) (next string, err error) {
+	var msg *dnsmessage.Message
+
+	msg, err = query(ctx, r, nameserver, name, resolver.TypeNSEC)
+	if err != nil {
+		return
+	}
+
+	for _, record := range append(msg.Answers, msg.Authorities...) {
+		body, ok := record.Body.(*dnsmessage.UnknownResource)
+
+		if !ok || record.Header.Type != resolver.TypeNSEC || !strings.EqualFold(record.Header.Name.String(), name) {
+			continue
+		}
+
+		next, err = resolver.ParseNSEC(body.Data)
+
+		return
+	}
+
+	err = fmt.Errorf("%w for %s", ErrNoNSEC, name)
+
+	return
+}
+
+// synthetic reports whether the NSEC record owned by owner, naming next, is
+// synthesized on the fly: "black lies" and minimally covering records (RFC
+// 4470) name the immediate successor of the name queried, `\000.<name>`, as
+// the next name, or its immediate predecessor, padded with `\255` octets.
+func synthetic(owner, next string) bool {
+	if strings.HasPrefix(next, "\x00.") {
+		return true
+	}
+
+	return strings.Contains(owner, "\xff") || strings.Contains(next, "\xff")
+}
+
+// collectNSEC3 collects the NSEC3 hashes of the zone from the answers to
+// queries for random names, then cracks them against the wordlist.
+func (source *Source) collectNSEC3(ctx context.Context, cfg *sources.Configuration, nameserver, domain string, results chan sources.Result) (err error) {
+	maxQueries := cfg.ZoneWalk.NSEC3Queries
+
+	if maxQueries < 1 {
+		maxQueries = defaultNSEC3Queries
+	}
+
+	// next maps the hash of every owner name seen to the hash of the next one.
+	next := map[string]string{}
+
+	var params resolver.NSEC3
+
+	misses := 0
+
+	for range maxQueries {
+		if ctx.Err() != nil || misses >= nsec3Patience || closed(next) {
+			break
+		}
+
+		var msg *dnsmessage.Message
+
+		msg, err = query(ctx, cfg.Resolver, nameserver, resolver.RandomLabel()+"."+domain, dnsmessage.TypeA)
+		if err != nil {
+			return
+		}
+
+		misses++
+
+		for _, record := range msg.Authorities {
+			body, ok := record.Body.(*dnsmessage.UnknownResource)
+			if !ok || record.Header.Type != resolver.TypeNSEC3 {
+				continue
+			}
+
+			var nsec3 resolver.NSEC3
+
+			nsec3, err = resolver.ParseNSEC3(record.Header.Name.String(), body.Data)
+			if err != nil {
+				return
+			}
+
+			if _, ok := next[nsec3.Hash]; !ok {
+				misses = 0
+			}
+
+			next[nsec3.Hash] = nsec3.Next
+			params = nsec3
+		}
+	}
+
+	if len(next) == 0 {
+		err = ErrNoNSEC
+
+		return
+	}
+
+	hashes := map[string]struct{}{}
+
+	for hash, nextHash := range next {
+		hashes[hash] = struct{}{}
+		hashes[nextHash] = struct{}{}
+	}
+
+	if cfg.ZoneWalk.HashesFile != "" {
+		if err := writeHashes(cfg.ZoneWalk.HashesFile, domain, params, hashes); err != nil {
+			source.error(err, results)
+		}
+	}
+
+	if !params.Supported() {
+		err = fmt.Errorf("unsupported NSEC3 hash algorithm %d", params.Algorithm)
+
+		return
+	}
+
+	var words []string
+
+	words, err = wordlist.Read(cfg.ZoneWalk.Wordlist)
+	if err != nil {
+		return
+	}
+
+	for _, word := range words {
+		if ctx.Err() != nil {
+			return
+		}
+
+		name := word + "." + domain
+
+		var hash string
+
+		hash, err = resolver.HashNSEC3(name, params.Salt, params.Iterations)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := hashes[hash]; ok {
+			source.subdomain(name, results)
+		}
+	}
+
+	err = nil
+
+	return
+}
+
+// closed reports whether the NSEC3 hashes collected form a complete chain,
+// i.e. every hash of the zone is known.
+func closed(next map[string]string) bool {
+	if len(next) == 0 {
+		return false
+	}
+
+	for _, hash := range next {
+		if _, ok := next[hash]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeHashes appends hashes to path in the hashcat (mode 8300) format:
+// `hash:.zone:salt:iterations`.
+func writeHashes(path, domain string, params resolver.NSEC3, hashes map[string]struct{}) (err error) {
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return
+	}
+
+	var file *os.File
+
+	file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	for hash := range hashes {
+		fmt.Fprintf(writer, "%s:.%s:%s:%d\n", hash, domain, hex.EncodeToString(params.Salt), params.Iterations)
+	}
+
+	err = writer.Flush()
+
+	return
+}
+
+// query sends a DNSSEC query directly to nameserver.
+func query(ctx context.Context, r *resolver.Resolver, nameserver, name string, qtype dnsmessage.Type) (msg *dnsmessage.Message, err error) {
+	question := dnsmessage.Question{
+		Type:  qtype,
+		Class: dnsmessage.ClassINET,
+	}
+
+	question.Name, err = dnsmessage.NewName(strings.TrimSuffix(name, ".") + ".")
+	if err != nil {
+		return
+	}
+
+	msg, err = r.Exchange(ctx, nameserver, question, true)
+
+	return
+}
+
+// recordNames returns the owner name of record and, for records pointing to
+// other names, e.g. CNAME or MX, the name it points to.
+func recordNames(record dnsmessage.Resource) (names []string) {
+	names = append(names, record.Header.Name.String())
+
+	switch body := record.Body.(type) {
+	case *dnsmessage.CNAMEResource:
+		names = append(names, body.CNAME.String())
+	case *dnsmessage.MXResource:
+		names = append(names, body.MX.String())
+	case *dnsmessage.NSResource:
+		names = append(names, body.NS.String())
+	case *dnsmessage.SRVResource:
+		names = append(names, body.Target.String())
+	}
+
+	return
+}
+
+func (source *Source) subdomain(name string, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultSubdomain,
+		Source: source.Name(),
+		Value:  name,
+	}
+
+	results <- result
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.ZONEWALK
+}
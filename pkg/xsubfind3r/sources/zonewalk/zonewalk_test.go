@@ -0,0 +1,204 @@
+package zonewalk
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// authoritative is a local authoritative nameserver of example.com, over UDP
+// only, so that zone transfers are refused. It serves the NSEC chain of names,
+// or, if blackLies is set, synthesizes NSEC records on the fly the way some
+// DNS providers do.
+type authoritative struct {
+	conn net.PacketConn
+
+	names     []string
+	blackLies bool
+}
+
+func newAuthoritative(t *testing.T, blackLies bool, names ...string) (server *authoritative) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server = &authoritative{
+		conn:      conn,
+		names:     names,
+		blackLies: blackLies,
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	go server.serve()
+
+	return
+}
+
+func (server *authoritative) serve() {
+	buffer := make([]byte, 65535)
+
+	for {
+		n, address, err := server.conn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+
+		var query dnsmessage.Message
+
+		if err = query.Unpack(buffer[:n]); err != nil || len(query.Questions) != 1 {
+			continue
+		}
+
+		answer := server.answer(query)
+
+		response, err := answer.Pack()
+		if err != nil {
+			continue
+		}
+
+		server.conn.WriteTo(response, address)
+	}
+}
+
+func (server *authoritative) answer(query dnsmessage.Message) (response dnsmessage.Message) {
+	question := query.Questions[0]
+
+	name := strings.ToLower(question.Name.String())
+
+	response.Header = dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true}
+	response.Questions = query.Questions
+
+	if server.blackLies {
+		// Every name exists, without records of the type queried, and its
+		// NSEC record only covers it.
+		response.Authorities = append(response.Authorities, nsec(name, "\x00."+name))
+
+		return
+	}
+
+	index := slices.Index(server.names, name)
+
+	if index == -1 {
+		response.RCode = dnsmessage.RCodeNameError
+		response.Authorities = append(response.Authorities, nsec(server.names[len(server.names)-1], server.names[0]))
+
+		return
+	}
+
+	if question.Type == resolver.TypeNSEC {
+		response.Answers = append(response.Answers, nsec(name, server.names[(index+1)%len(server.names)]))
+	}
+
+	return
+}
+
+// nsec returns the NSEC record owned by owner, naming next.
+func nsec(owner, next string) (record dnsmessage.Resource) {
+	var data []byte
+
+	for _, label := range strings.Split(strings.TrimSuffix(next, "."), ".") {
+		data = append(data, byte(len(label)))
+		data = append(data, label...)
+	}
+
+	// The root label, then a type bitmap of A and RRSIG.
+	data = append(data, 0, 0, 6, 0x40, 0, 0, 0, 0, 0x02)
+
+	record = dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName(owner),
+			Class: dnsmessage.ClassINET,
+			TTL:   300,
+		},
+		Body: &dnsmessage.UnknownResource{Type: resolver.TypeNSEC, Data: data},
+	}
+
+	return
+}
+
+func run(t *testing.T, server *authoritative) (subdomains []string, errs []error) {
+	t.Helper()
+
+	cfg := &sources.Configuration{
+		Resolver: resolver.New(&resolver.Configuration{Timeout: time.Second}),
+		ZoneWalk: sources.ZoneWalkConfiguration{
+			Nameservers: []string{server.conn.LocalAddr().String()},
+		},
+	}
+
+	source := &Source{}
+
+	for result := range source.Run(context.Background(), cfg, "example.com") {
+		switch result.Type {
+		case sources.ResultSubdomain:
+			subdomains = append(subdomains, result.Value)
+		case sources.ResultError:
+			errs = append(errs, result.Error)
+		}
+	}
+
+	return
+}
+
+func TestWalkNSEC(t *testing.T) {
+	server := newAuthoritative(t, false, "example.com.", "_dmarc.example.com.", "api.example.com.", "www.example.com.")
+
+	subdomains, errs := run(t, server)
+
+	if len(errs) != 0 {
+		t.Errorf("errors = %v, want none", errs)
+	}
+
+	want := []string{"_dmarc.example.com.", "api.example.com.", "www.example.com."}
+
+	if !slices.Equal(subdomains, want) {
+		t.Errorf("subdomains = %v, want %v", subdomains, want)
+	}
+}
+
+func TestWalkNSECStopsOnBlackLies(t *testing.T) {
+	server := newAuthoritative(t, true)
+
+	subdomains, errs := run(t, server)
+
+	if len(subdomains) != 0 {
+		t.Errorf("subdomains = %q, want none", subdomains)
+	}
+
+	if !slices.ContainsFunc(errs, func(err error) bool { return errors.Is(err, ErrSyntheticNSEC) }) {
+		t.Errorf("errors = %v, want ErrSyntheticNSEC", errs)
+	}
+}
+
+func TestSynthetic(t *testing.T) {
+	tests := []struct {
+		owner, next string
+		want        bool
+	}{
+		{"example.com.", "api.example.com.", false},
+		{"www.example.com.", "example.com.", false},
+		{"api.example.com.", "\x00.api.example.com.", true},
+		{"aph\xff\xff.example.com.", "\x00.api.example.com.", true},
+		{"apg\xff\xff.example.com.", "apj.example.com.", true},
+	}
+
+	for _, test := range tests {
+		if got := synthetic(test.owner, test.next); got != test.want {
+			t.Errorf("synthetic(%q, %q) = %v, want %v", test.owner, test.next, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package zoomeye
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hueristiq/xsubfind3r/pkg/httpclient"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+type domainSearchResponse struct {
+	Status int `json:"status"`
+	Total  int `json:"total"`
+	List   []struct {
+		Name string `json:"name"`
+	} `json:"list"`
+}
+
+type Source struct{}
+
+const defaultMaxPages = 10
+
+func (source *Source) Run(ctx context.Context, cfg *sources.Configuration, domain string) <-chan sources.Result {
+	results := make(chan sources.Result)
+
+	go func() {
+		defer close(results)
+
+		key, err := cfg.Keys.ZoomEye.PickRandom()
+		if key == "" || err != nil {
+			source.error(err, results)
+
+			return
+		}
+
+		maxPages := cfg.HostSearch.MaxPages
+
+		if maxPages < 1 {
+			maxPages = defaultMaxPages
+		}
+
+		domainSearchReqHeaders := map[string]string{
+			"API-KEY": key,
+		}
+
+		listed := 0
+
+		for page := 1; page <= maxPages; page++ {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// `type=1` lists subdomains, `type=0` associated domains.
+			domainSearchReqURL := fmt.Sprintf("https://api.zoomeye.ai/domain/search?q=%s&type=1&page=%d", url.QueryEscape(domain), page)
+
+			var domainSearchRes *http.Response
+
+			domainSearchRes, err = httpclient.Get(ctx, domainSearchReqURL, "", domainSearchReqHeaders)
+			if err != nil {
+				source.error(err, results)
+
+				httpclient.DiscardResponse(domainSearchRes)
+
+				return
+			}
+
+			var domainSearchResData domainSearchResponse
+
+			if err = json.NewDecoder(domainSearchRes.Body).Decode(&domainSearchResData); err != nil {
+				source.error(err, results)
+
+				domainSearchRes.Body.Close()
+
+				return
+			}
+
+			domainSearchRes.Body.Close()
+
+			for _, record := range domainSearchResData.List {
+				subdomain := strings.ToLower(record.Name)
+
+				if subdomain != domain && !strings.HasSuffix(subdomain, "."+domain) {
+					continue
+				}
+
+				result := sources.Result{
+					Type:   sources.ResultSubdomain,
+					Source: source.Name(),
+					Value:  subdomain,
+				}
+
+				results <- result
+			}
+
+			listed += len(domainSearchResData.List)
+
+			if len(domainSearchResData.List) == 0 || listed >= domainSearchResData.Total {
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+func (source *Source) error(err error, results chan sources.Result) {
+	result := sources.Result{
+		Type:   sources.ResultError,
+		Source: source.Name(),
+		Error:  err,
+	}
+
+	results <- result
+}
+
+func (source *Source) Name() string {
+	return sources.ZOOMEYE
+}
@@ -0,0 +1,397 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/normalizer"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+
+	// The SQLite driver is pure Go, so SQLite output needs no cgo.
+	_ "modernc.org/sqlite"
+)
+
+// Store records the results of scans in a SQLite database, appending each
+// scan to those of previous runs. Results are written in a transaction per
+// root domain, committed by Flush. It is safe for concurrent use.
+type Store struct {
+	mutex sync.Mutex
+
+	db *sql.DB
+	tx *sql.Tx
+
+	scan  int64
+	roots map[string]int64
+}
+
+// driver is the name of the database/sql driver used.
+const driver = "sqlite"
+
+// timeLayout is the layout of timestamps, that of SQLite date and time
+// functions, so that e.g. `started_at >= datetime('now', '-1 month')` works.
+const timeLayout = "2006-01-02 15:04:05"
+
+// schema creates the tables of the database, if it is new.
+const schema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id          INTEGER PRIMARY KEY,
+	version     TEXT NOT NULL,
+	arguments   TEXT NOT NULL,
+	started_at  TEXT NOT NULL,
+	finished_at TEXT,
+	interrupted INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS root_domains (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS scan_root_domains (
+	scan_id        INTEGER NOT NULL REFERENCES scans (id),
+	root_domain_id INTEGER NOT NULL REFERENCES root_domains (id),
+	PRIMARY KEY (scan_id, root_domain_id)
+);
+
+CREATE TABLE IF NOT EXISTS subdomains (
+	id             INTEGER PRIMARY KEY,
+	root_domain_id INTEGER NOT NULL REFERENCES root_domains (id),
+	name           TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS attributions (
+	scan_id      INTEGER NOT NULL REFERENCES scans (id),
+	subdomain_id INTEGER NOT NULL REFERENCES subdomains (id),
+	source       TEXT NOT NULL,
+	evidence     TEXT,
+	first_seen   TEXT,
+	last_seen    TEXT,
+	PRIMARY KEY (scan_id, subdomain_id, source)
+);
+
+CREATE INDEX IF NOT EXISTS attributions_source ON attributions (source);
+
+CREATE TABLE IF NOT EXISTS errors (
+	id             INTEGER PRIMARY KEY,
+	scan_id        INTEGER NOT NULL REFERENCES scans (id),
+	root_domain_id INTEGER NOT NULL REFERENCES root_domains (id),
+	source         TEXT NOT NULL,
+	message        TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS addresses (
+	scan_id      INTEGER NOT NULL REFERENCES scans (id),
+	subdomain_id INTEGER NOT NULL REFERENCES subdomains (id),
+	ip           TEXT NOT NULL,
+	asn          INTEGER,
+	organization TEXT,
+	country      TEXT,
+	cidr         TEXT,
+	PRIMARY KEY (scan_id, subdomain_id, ip)
+);
+
+CREATE TABLE IF NOT EXISTS cnames (
+	scan_id        INTEGER NOT NULL REFERENCES scans (id),
+	root_domain_id INTEGER NOT NULL REFERENCES root_domains (id),
+	name           TEXT NOT NULL,
+	target         TEXT NOT NULL,
+	PRIMARY KEY (scan_id, name, target)
+);
+
+CREATE TABLE IF NOT EXISTS responses (
+	scan_id            INTEGER NOT NULL REFERENCES scans (id),
+	subdomain_id       INTEGER NOT NULL REFERENCES subdomains (id),
+	url                TEXT NOT NULL,
+	status_code        INTEGER NOT NULL,
+	title              TEXT,
+	content_length     INTEGER,
+	server             TEXT,
+	final_url          TEXT,
+	simhash            TEXT,
+	header_fingerprint TEXT,
+	cluster            INTEGER,
+	PRIMARY KEY (scan_id, url)
+);
+`
+
+// Open opens, or creates, the database at path, and records the start of a
+// new scan by version run with arguments.
+func Open(path, version string, arguments []string) (store *Store, err error) {
+	var db *sql.DB
+
+	// Waiting on locks lets concurrent runs append to the same database.
+	db, err = sql.Open(driver, path+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return
+	}
+
+	if _, err = db.Exec(schema); err != nil {
+		db.Close()
+
+		return
+	}
+
+	var result sql.Result
+
+	result, err = db.Exec(
+		"INSERT INTO scans (version, arguments, started_at) VALUES (?, ?, ?)",
+		version, strings.Join(arguments, " "), now(),
+	)
+	if err != nil {
+		db.Close()
+
+		return
+	}
+
+	store = &Store{
+		db:    db,
+		roots: map[string]int64{},
+	}
+
+	store.scan, err = result.LastInsertId()
+	if err != nil {
+		db.Close()
+
+		return
+	}
+
+	return
+}
+
+// Add records result, found for the root domain domain. Results other than
+// subdomains, attributions, errors, addresses, CNAMEs and probes are ignored,
+// and so are rejected candidates.
+func (store *Store) Add(domain string, result sources.Result) (err error) {
+	store.mutex.Lock()
+
+	defer store.mutex.Unlock()
+
+	if store.tx == nil {
+		store.tx, err = store.db.Begin()
+		if err != nil {
+			return
+		}
+	}
+
+	var root int64
+
+	root, err = store.root(domain)
+	if err != nil {
+		return
+	}
+
+	switch result.Type {
+	case sources.ResultSubdomain, sources.ResultAttribution:
+		err = store.attribution(root, result)
+	case sources.ResultError:
+		var rejection *normalizer.RejectionError
+
+		if result.Error == nil || errors.As(result.Error, &rejection) {
+			return
+		}
+
+		_, err = store.tx.Exec(
+			"INSERT INTO errors (scan_id, root_domain_id, source, message) VALUES (?, ?, ?, ?)",
+			store.scan, root, result.Source, result.Error.Error(),
+		)
+	case sources.ResultIP:
+		var subdomain int64
+
+		subdomain, err = store.subdomain(root, result.Subdomain)
+		if err != nil {
+			return
+		}
+
+		err = store.address(subdomain, sources.Address{IP: result.Value})
+	case sources.ResultCNAME:
+		_, err = store.tx.Exec(
+			"INSERT OR IGNORE INTO cnames (scan_id, root_domain_id, name, target) VALUES (?, ?, ?, ?)",
+			store.scan, root, result.Subdomain, result.Value,
+		)
+	case sources.ResultProbe:
+		err = store.response(root, result)
+	}
+
+	return
+}
+
+// root returns the ID of the root domain named domain, recording it, and
+// that it is part of the scan, on first use.
+func (store *Store) root(domain string) (id int64, err error) {
+	id, ok := store.roots[domain]
+	if ok {
+		return
+	}
+
+	if _, err = store.tx.Exec("INSERT OR IGNORE INTO root_domains (name) VALUES (?)", domain); err != nil {
+		return
+	}
+
+	if err = store.tx.QueryRow("SELECT id FROM root_domains WHERE name = ?", domain).Scan(&id); err != nil {
+		return
+	}
+
+	if _, err = store.tx.Exec("INSERT OR IGNORE INTO scan_root_domains (scan_id, root_domain_id) VALUES (?, ?)", store.scan, id); err != nil {
+		return
+	}
+
+	store.roots[domain] = id
+
+	return
+}
+
+// subdomain returns the ID of the subdomain named name, recording it on first
+// sight.
+func (store *Store) subdomain(root int64, name string) (id int64, err error) {
+	if _, err = store.tx.Exec("INSERT OR IGNORE INTO subdomains (root_domain_id, name) VALUES (?, ?)", root, name); err != nil {
+		return
+	}
+
+	err = store.tx.QueryRow("SELECT id FROM subdomains WHERE name = ?", name).Scan(&id)
+
+	return
+}
+
+// attribution records that the source of result found the subdomain of
+// result, with its addresses if enriched.
+func (store *Store) attribution(root int64, result sources.Result) (err error) {
+	var subdomain int64
+
+	subdomain, err = store.subdomain(root, result.Value)
+	if err != nil {
+		return
+	}
+
+	_, err = store.tx.Exec(
+		"INSERT OR IGNORE INTO attributions (scan_id, subdomain_id, source, evidence, first_seen, last_seen) VALUES (?, ?, ?, ?, ?, ?)",
+		store.scan, subdomain, result.Source, nullable(result.Evidence), timestamp(result.FirstSeen), timestamp(result.LastSeen),
+	)
+	if err != nil {
+		return
+	}
+
+	for _, address := range result.Addresses {
+		if err = store.address(subdomain, address); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// address records an address of a subdomain, completing what is known of
+// its network if it was recorded before.
+func (store *Store) address(subdomain int64, address sources.Address) (err error) {
+	var asn any
+
+	if address.ASN != 0 {
+		asn = int64(address.ASN)
+	}
+
+	_, err = store.tx.Exec(
+		`INSERT INTO addresses (scan_id, subdomain_id, ip, asn, organization, country, cidr) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (scan_id, subdomain_id, ip) DO UPDATE SET
+			asn = coalesce(excluded.asn, asn),
+			organization = coalesce(excluded.organization, organization),
+			country = coalesce(excluded.country, country),
+			cidr = coalesce(excluded.cidr, cidr)`,
+		store.scan, subdomain, address.IP, asn, nullable(address.Organization), nullable(address.Country), nullable(address.CIDR),
+	)
+
+	return
+}
+
+// response records the response of the web server of a probe result.
+func (store *Store) response(root int64, result sources.Result) (err error) {
+	if result.Response == nil {
+		return
+	}
+
+	var subdomain int64
+
+	subdomain, err = store.subdomain(root, result.Subdomain)
+	if err != nil {
+		return
+	}
+
+	var cluster any
+
+	if result.Cluster != nil {
+		cluster = result.Cluster.ID
+	}
+
+	_, err = store.tx.Exec(
+		`INSERT OR IGNORE INTO responses (scan_id, subdomain_id, url, status_code, title, content_length, server, final_url, simhash, header_fingerprint, cluster)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		store.scan, subdomain, result.Value, result.Response.StatusCode, nullable(result.Response.Title), result.Response.ContentLength,
+		nullable(result.Response.Server), result.Response.FinalURL, fmt.Sprintf("%016x", result.Response.SimHash), result.Response.HeaderFingerprint, cluster,
+	)
+
+	return
+}
+
+// Flush commits the results added so far.
+func (store *Store) Flush() (err error) {
+	store.mutex.Lock()
+
+	defer store.mutex.Unlock()
+
+	return store.commit()
+}
+
+func (store *Store) commit() (err error) {
+	if store.tx == nil {
+		return
+	}
+
+	err = store.tx.Commit()
+
+	store.tx = nil
+
+	return
+}
+
+// Close commits the results added, records the end of the scan, and whether
+// it was interrupted, then closes the database.
+func (store *Store) Close(interrupted bool) (err error) {
+	store.mutex.Lock()
+
+	defer store.mutex.Unlock()
+
+	defer store.db.Close()
+
+	if err = store.commit(); err != nil {
+		return
+	}
+
+	_, err = store.db.Exec("UPDATE scans SET finished_at = ?, interrupted = ? WHERE id = ?", now(), interrupted, store.scan)
+
+	return
+}
+
+// now returns the current time, as stored.
+func now() string {
+	return time.Now().UTC().Format(timeLayout)
+}
+
+// timestamp returns t as stored, or NULL if it is zero.
+func timestamp(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(timeLayout)
+}
+
+// nullable returns value, or NULL if it is empty.
+func nullable(value string) any {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
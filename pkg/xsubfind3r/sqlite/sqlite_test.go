@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+func TestStoreAppendsScans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xsubfind3r.db")
+
+	for _, source := range []string{"crtsh", "github"} {
+		store, err := Open(path, "0.0.0", []string{"-d", "example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var foreignKeys int
+
+		if err = store.db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil || foreignKeys != 1 {
+			t.Errorf("foreign_keys = %d, %v, want enforced", foreignKeys, err)
+		}
+
+		result := sources.Result{Type: sources.ResultSubdomain, Source: source, Value: "www.example.com"}
+
+		if err = store.Add("example.com", result); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = store.Close(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := Open(path, "0.0.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		store.Close(false)
+	})
+
+	var scans, subdomains, finished int
+
+	if err = store.db.QueryRow("SELECT COUNT(*), COUNT(finished_at) FROM scans").Scan(&scans, &finished); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.db.QueryRow("SELECT COUNT(*) FROM subdomains").Scan(&subdomains); err != nil {
+		t.Fatal(err)
+	}
+
+	// The scan just opened is not finished yet.
+	if scans != 3 || finished != 2 || subdomains != 1 {
+		t.Errorf("scans = %d, finished = %d, subdomains = %d, want 3, 2 and 1", scans, finished, subdomains)
+	}
+}
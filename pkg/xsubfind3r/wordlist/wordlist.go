@@ -0,0 +1,59 @@
+package wordlist
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"os"
+	"strings"
+)
+
+// builtin is a small wordlist of common subdomain labels, used when no
+// wordlist is given.
+//
+//go:embed wordlist.txt
+var builtin string
+
+// Read reads the words of the wordlist at path, or of the built-in wordlist
+// if path is empty. Words are lowercased and deduplicated, empty lines and
+// `#` comments are skipped.
+func Read(path string) (words []string, err error) {
+	var reader io.Reader = strings.NewReader(builtin)
+
+	if path != "" {
+		var file *os.File
+
+		file, err = os.Open(path)
+		if err != nil {
+			return
+		}
+
+		defer file.Close()
+
+		reader = file
+	}
+
+	seen := map[string]struct{}{}
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		word := strings.ToLower(strings.Trim(strings.TrimSpace(scanner.Text()), "."))
+
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+
+		if _, ok := seen[word]; ok {
+			continue
+		}
+
+		seen[word] = struct{}{}
+
+		words = append(words, word)
+	}
+
+	err = scanner.Err()
+
+	return
+}
@@ -1,32 +1,61 @@
 package xsubfind3r
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"sync"
 
 	hqgourl "github.com/hueristiq/hq-go-url"
+	"github.com/hueristiq/xsubfind3r/pkg/resolver"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/checkpoint"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/cluster"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/enrichment"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/filter"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/graph"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/normalizer"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/probe"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/anubis"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/bevigil"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/bruteforce"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/builtwith"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/censys"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/certificatedetails"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/certspotter"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/chaos"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/circl"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/commoncrawl"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/crawl"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/crtsh"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/ctlog"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/dnsdb"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/dnsrecords"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/fofa"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/fullhunt"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/github"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/gitlab"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/hackertarget"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/hunter"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/importer"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/intelx"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/leakix"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/localfs"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/mnemonic"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/netlas"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/otx"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/permutation"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/securitytrails"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/shodan"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/subdomaincenter"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/takeover"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/tlsgrab"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/urlscan"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/virustotal"
 	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/wayback"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/whois"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/zonewalk"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources/zoomeye"
 )
 
 // Finder is the main structure that manages the interaction with OSINT sources.
@@ -35,20 +64,62 @@ type Finder struct {
 	// sources is a map of source names to their corresponding implementations.
 	// Each source implements the Source interface, which allows domain searches.
 	sources map[string]sources.Source
+	// stages is a map of stage names to their implementations, they run on
+	// the subdomains found by the sources once those are done.
+	stages map[string]sources.Stage
+	// takeover, if set, checks the subdomains kept for takeovers, once all are
+	// sent, as its fingerprints are matched against their final list.
+	takeover sources.Stage
 	// configuration contains configuration options such as API keys
 	// and other settings needed by the data sources.
 	configuration *sources.Configuration
+	// checkpoint, if set, records finished sources so they are skipped on resume.
+	checkpoint *checkpoint.Checkpoint
+	// filter decides which of the deduplicated subdomains are kept.
+	filter *filter.Filter
+	// normalizer canonicalizes and validates subdomains before deduplication.
+	normalizer *normalizer.Normalizer
+	// enricher, if set, attaches their addresses to subdomains before they are sent.
+	enricher *enrichment.Enricher
+	// prober, if set, probes the web servers of the subdomains kept, once all are sent.
+	prober *probe.Prober
+	// cluster, if true, groups the responses probed into clusters of similar
+	// ones before they are sent, maxDistance apart at most, the default if negative.
+	cluster     bool
+	maxDistance int
+	// attributions, if true, reports every source that found a subdomain kept,
+	// not only the first.
+	attributions bool
+	// graph, if set, aggregates the results sent into a graph of the namespace
+	// of the domains searched.
+	graph *graph.Graph
 }
 
 // Find takes a domain name and starts the subdomain search process across all
 // the sources specified in the configuration. It returns a channel through which
 // the search results (of type Result) are streamed asynchronously.
-func (finder *Finder) Find(domain string) (results chan sources.Result) {
+// Once all sources have returned, stages run on the subdomains they found.
+// If probing is on, the web servers of the subdomains kept are probed last,
+// and, if clustering is on, their responses grouped by similarity.
+// If a graph is set, the results sent are added to it.
+// Canceling ctx stops all sources and stages; the channel is closed once they have returned.
+func (finder *Finder) Find(ctx context.Context, domain string) (results chan sources.Result) {
 	// Initialize the results channel where subdomain findings are sent.
 	results = make(chan sources.Result)
 
-	// Parse the given domain using a domain parser.
-	parsed := dp.Parse(domain)
+	// sent is the channel results are sent down, through the graph if set.
+	sent := results
+
+	// written are the subdomains written out before a resume, checkpoints
+	// record them under the domain as given.
+	var written []string
+
+	if finder.checkpoint != nil {
+		written = finder.checkpoint.Written(domain)
+	}
+
+	// Parse the given domain, in its punycode form, using a domain parser.
+	parsed := dp.Parse(normalizer.Domain(domain))
 
 	// Rebuild the domain as "root.tld" format.
 	domain = parsed.Root + "." + parsed.TopLevel
@@ -58,19 +129,56 @@ func (finder *Finder) Find(domain string) (results chan sources.Result) {
 		hqgourl.DomainExtractorWithTLDPattern(parsed.TopLevel),
 	).CompileRegex()
 
+	if finder.graph != nil {
+		sent = make(chan sources.Result)
+
+		go func() {
+			defer close(results)
+
+			for result := range sent {
+				finder.graph.Add(domain, result)
+
+				results <- result
+			}
+		}()
+	}
+
 	// Launch a goroutine to perform the search concurrently across all sources.
-	go func() {
+	go func(results chan sources.Result) {
 		// Ensure the results channel is closed once all search operations complete.
 		defer close(results)
 
-		// A thread-safe map to store already-seen subdomains, avoiding duplicates.
-		seenSubdomains := &sync.Map{}
+		// A thread-safe map to store already-seen results, avoiding duplicates.
+		seen := &sync.Map{}
+
+		// found collects the subdomains kept, those kept from sources are the input of stages.
+		// On resume, those of the sources done are those written out before.
+		found := &collected{}
+
+		for _, subdomain := range written {
+			found.add(subdomain)
+		}
+
+		// held keeps subdomains back until they are enriched, once all are found.
+		var held *pending
+
+		if finder.enricher != nil {
+			held = &pending{
+				ips:          map[string][]string{},
+				attributions: map[string][]sources.Result{},
+			}
+		}
 
 		// WaitGroup ensures all source goroutines finish before exiting.
 		wg := &sync.WaitGroup{}
 
 		// Iterate over all the sources in the Finder.
 		for _, source := range finder.sources {
+			// Skip sources that already ran to completion in a resumed run.
+			if finder.checkpoint != nil && finder.checkpoint.IsSourceDone(domain, source.Name()) {
+				continue
+			}
+
 			wg.Add(1)
 
 			// Start a new goroutine for each source to fetch subdomains concurrently.
@@ -79,47 +187,441 @@ func (finder *Finder) Find(domain string) (results chan sources.Result) {
 				defer wg.Done()
 
 				// Call the source's Run method to start the subdomain search.
-				sResults := source.Run(finder.configuration, domain)
-
-				// Process each result as it's received from the source.
-				for sResult := range sResults {
-					// If the result is a subdomain, process it.
-					if sResult.Type == sources.ResultSubdomain {
-						// Convert the subdomain to lowercase and strip any wildcards (e.g., "*.")
-						sResult.Value = strings.ToLower(sResult.Value)
-						sResult.Value = strings.ReplaceAll(sResult.Value, "*.", "")
-
-						// Check if the subdomain has already been seen using sync.Map.
-						_, loaded := seenSubdomains.LoadOrStore(sResult.Value, struct{}{})
-						if loaded {
-							// If the subdomain is already in the map, skip it.
-							continue
-						}
-					}
+				sResults := source.Run(ctx, finder.configuration, domain)
 
-					// Send the result down the results channel.
-					results <- sResult
-				}
+				finder.process(ctx, domain, source.Name(), sResults, results, seen, found, held)
 			}(source)
 		}
 
 		// Wait for all goroutines to finish before exiting.
 		wg.Wait()
-	}()
+
+		// Stages build on what the sources found, so they run once those are done.
+		subdomains := found.list()
+
+		for _, stage := range finder.stages {
+			if ctx.Err() != nil {
+				break
+			}
+
+			if finder.checkpoint != nil && finder.checkpoint.IsSourceDone(domain, stage.Name()) {
+				continue
+			}
+
+			wg.Add(1)
+
+			go func(stage sources.Stage) {
+				defer wg.Done()
+
+				sResults := stage.Run(ctx, finder.configuration, domain, subdomains)
+
+				finder.process(ctx, domain, stage.Name(), sResults, results, seen, found, held)
+			}(stage)
+		}
+
+		wg.Wait()
+
+		kept := found.list()
+
+		if held != nil {
+			// Subdomains written out before a resume were enriched, and kept, then.
+			enriched := &collected{}
+
+			for _, subdomain := range append(written, finder.enrich(ctx, held, results)...) {
+				enriched.add(subdomain)
+			}
+
+			kept = enriched.list()
+		}
+
+		if finder.takeover != nil && ctx.Err() == nil && (finder.checkpoint == nil || !finder.checkpoint.IsSourceDone(domain, finder.takeover.Name())) {
+			sResults := finder.takeover.Run(ctx, finder.configuration, domain, kept)
+
+			finder.process(ctx, domain, finder.takeover.Name(), sResults, results, seen, found, nil)
+		}
+
+		// Probing has its own concurrency, web servers are slower to answer than APIs.
+		if finder.prober != nil && ctx.Err() == nil {
+			finder.probe(ctx, kept, results)
+		}
+	}(sent)
 
 	// Return the channel that will stream subdomain results.
 	return
 }
 
+// process normalizes, deduplicates and filters the results of a source or a
+// stage named name, sending those kept down results. Subdomains kept are also
+// added to found. If held is not nil, subdomains and the addresses
+// found with them are kept back in it, to be enriched.
+// Once the results are drained, an info result tells how many subdomains it
+// added.
+func (finder *Finder) process(ctx context.Context, domain, name string, sResults <-chan sources.Result, results chan sources.Result, seen *sync.Map, found *collected, held *pending) {
+	added := 0
+
+	// failed tells the source reported an error, e.g. it was rate limited or
+	// timed out, and may not have returned all it would have.
+	failed := false
+
+	// Process each result as it's received from the source.
+	for sResult := range sResults {
+		switch sResult.Type {
+		case sources.ResultError:
+			failed = true
+		case sources.ResultCNAME:
+			sResult.Value = strings.TrimSuffix(strings.ToLower(sResult.Value), ".")
+
+			if _, loaded := seen.LoadOrStore(seenKey{sResult.Type, sResult.Value + " " + sResult.Subdomain}, struct{}{}); loaded {
+				continue
+			}
+		case sources.ResultIP:
+			// Addresses are kept for the subdomains kept, once per pair.
+			subdomain, err := finder.normalizer.Normalize(sResult.Subdomain, domain)
+			if err != nil || !finder.filter.Allow(subdomain) {
+				continue
+			}
+
+			sResult.Subdomain = subdomain
+
+			if _, loaded := seen.LoadOrStore(seenKey{sResult.Type, sResult.Value + " " + subdomain}, struct{}{}); loaded {
+				continue
+			}
+
+			if held != nil {
+				held.addIP(subdomain, sResult.Value)
+
+				continue
+			}
+		case sources.ResultRelated, sources.ResultRootDomain, sources.ResultTakeover:
+			sResult.Value = strings.TrimSuffix(strings.ToLower(sResult.Value), ".")
+
+			// Related and root domains, and takeover candidates, are deduplicated apart from subdomains.
+			if _, loaded := seen.LoadOrStore(seenKey{sResult.Type, sResult.Value}, struct{}{}); loaded {
+				continue
+			}
+		case sources.ResultSubdomain:
+			// Normalize the subdomain, reporting rejected candidates as errors.
+			subdomain, err := finder.normalizer.Normalize(sResult.Value, domain)
+			if err != nil {
+				results <- sources.Result{
+					Type:   sources.ResultError,
+					Source: sResult.Source,
+					Error:  err,
+				}
+
+				continue
+			}
+
+			sResult.Value = subdomain
+
+			// Check if the subdomain has already been seen using sync.Map.
+			_, loaded := seen.LoadOrStore(seenKey{sResult.Type, sResult.Value}, struct{}{})
+			if loaded {
+				// If the subdomain is already in the map, skip it, only
+				// telling, once per source, which others found it too.
+				if finder.attributions && finder.filter.Allow(sResult.Value) {
+					if _, loaded = seen.LoadOrStore(seenKey{sources.ResultAttribution, sResult.Value + " " + sResult.Source}, struct{}{}); !loaded {
+						sResult.Type = sources.ResultAttribution
+
+						// Attributions of held subdomains wait for them to be kept.
+						if held != nil {
+							held.addAttribution(sResult)
+						} else {
+							results <- sResult
+						}
+					}
+				}
+
+				continue
+			}
+
+			// Drop subdomains excluded by the scope filter.
+			if !finder.filter.Allow(sResult.Value) {
+				continue
+			}
+
+			if finder.attributions {
+				seen.Store(seenKey{sources.ResultAttribution, sResult.Value + " " + sResult.Source}, struct{}{})
+			}
+
+			found.add(sResult.Value)
+
+			added++
+
+			if held != nil {
+				held.add(sResult)
+
+				continue
+			}
+		}
+
+		// Send the result down the results channel.
+		results <- sResult
+	}
+
+	if ctx.Err() == nil {
+		results <- sources.Result{
+			Type:   sources.ResultInfo,
+			Source: name,
+			Value:  fmt.Sprintf("done, %d new subdomain(s)", added),
+		}
+	}
+
+	// A source stopped by cancellation, or by an error, has not finished, it
+	// must run again, from its last cursor, on resume.
+	if finder.checkpoint != nil && ctx.Err() == nil && !failed {
+		finder.checkpoint.MarkSourceDone(domain, name)
+	}
+}
+
+// enrich attaches their addresses to the subdomains held, concurrently, and
+// sends them down results, followed by their attributions. Subdomains all of
+// whose addresses belong to excluded autonomous systems are dropped, with their
+// attributions, the others are returned.
+func (finder *Finder) enrich(ctx context.Context, held *pending, results chan sources.Result) (kept []string) {
+	jobs := make(chan sources.Result)
+
+	sent := &collected{}
+
+	wg := &sync.WaitGroup{}
+
+	for range finder.enricher.Concurrency() {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for result := range jobs {
+				// Interrupted runs send subdomains as they are: their sources
+				// are done, they would not be found again on resume.
+				if ctx.Err() != nil {
+					results <- result
+
+					held.attribute(result.Value, results)
+
+					continue
+				}
+
+				addresses, err := finder.enricher.Enrich(ctx, result.Value, held.ips[result.Value])
+				if err != nil && ctx.Err() == nil {
+					results <- sources.Result{
+						Type:   sources.ResultError,
+						Source: enrichment.NAME,
+						Error:  err,
+					}
+				}
+
+				if finder.enricher.Excluded(addresses) {
+					results <- sources.Result{
+						Type:   sources.ResultInfo,
+						Source: enrichment.NAME,
+						Value:  fmt.Sprintf("%s dropped, hosted on excluded AS%d", result.Value, addresses[0].ASN),
+					}
+
+					continue
+				}
+
+				result.Addresses = addresses
+
+				sent.add(result.Value)
+
+				results <- result
+
+				held.attribute(result.Value, results)
+			}
+		}()
+	}
+
+	for _, result := range held.subdomains {
+		jobs <- result
+	}
+
+	close(jobs)
+
+	wg.Wait()
+
+	return sent.list()
+}
+
+// probe probes the web servers of subdomains and sends their responses down
+// results. If clustering is on, responses are held until all are in, to be
+// grouped into clusters first.
+func (finder *Finder) probe(ctx context.Context, subdomains []string, results chan sources.Result) {
+	if !finder.cluster {
+		finder.prober.Probe(ctx, subdomains, results)
+
+		return
+	}
+
+	probed := make(chan sources.Result)
+
+	var responses []sources.Result
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for result := range probed {
+			responses = append(responses, result)
+		}
+	}()
+
+	finder.prober.Probe(ctx, subdomains, probed)
+
+	close(probed)
+
+	<-done
+
+	cluster.Assign(responses, finder.maxDistance)
+
+	for _, result := range responses {
+		results <- result
+	}
+}
+
+// seenKey is the key of results in the map used for deduplication.
+type seenKey struct {
+	kind  sources.ResultType
+	value string
+}
+
+// collected is a thread-safe list of distinct subdomains.
+type collected struct {
+	mutex      sync.Mutex
+	subdomains []string
+	index      map[string]struct{}
+}
+
+func (c *collected) add(subdomain string) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	if _, ok := c.index[subdomain]; ok {
+		return
+	}
+
+	if c.index == nil {
+		c.index = map[string]struct{}{}
+	}
+
+	c.index[subdomain] = struct{}{}
+
+	c.subdomains = append(c.subdomains, subdomain)
+}
+
+func (c *collected) list() []string {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.subdomains...)
+}
+
+// pending holds subdomains kept back to be enriched, with the IP addresses
+// sources found them with and the attributions of the other sources that did.
+type pending struct {
+	mutex        sync.Mutex
+	subdomains   []sources.Result
+	ips          map[string][]string
+	attributions map[string][]sources.Result
+}
+
+func (p *pending) add(result sources.Result) {
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	p.subdomains = append(p.subdomains, result)
+}
+
+func (p *pending) addIP(subdomain, ip string) {
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	p.ips[subdomain] = append(p.ips[subdomain], ip)
+}
+
+func (p *pending) addAttribution(result sources.Result) {
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	p.attributions[result.Value] = append(p.attributions[result.Value], result)
+}
+
+// attribute sends down results the attributions held for subdomain, once all
+// are found.
+func (p *pending) attribute(subdomain string, results chan sources.Result) {
+	for _, attribution := range p.attributions[subdomain] {
+		results <- attribution
+	}
+}
+
 // Configuration holds the configuration for Finder, including
 // the sources to use, sources to exclude, and the necessary API keys.
 type Configuration struct {
 	// SourcesToUse is a list of source names that should be used for the search.
 	SourcesToUSe []string
+	// SourcesToInclude is a list of source names used on top of the others,
+	// it is how opt-in sources are added to the default ones.
+	SourcesToInclude []string
 	// SourcesToExclude is a list of source names that should be excluded from the search.
 	SourcesToExclude []string
 	// Keys contains the API keys for each data source.
 	Keys sources.Keys
+	// CTLog holds the settings of the `ctlog` source.
+	CTLog sources.CTLogConfiguration
+	// GitLab holds the settings of the `gitlab` source.
+	GitLab sources.GitLabConfiguration
+	// LocalFS holds the settings of the `localfs` source.
+	LocalFS sources.LocalFSConfiguration
+	// Import holds the settings of the `import` source.
+	Import sources.ImportConfiguration
+	// PassiveDNS holds the settings of the passive DNS sources.
+	PassiveDNS sources.PassiveDNSConfiguration
+	// HostSearch holds the settings of the internet scan search engine sources.
+	HostSearch sources.HostSearchConfiguration
+	// Whois holds the settings of the `whois` stage.
+	Whois sources.WhoisConfiguration
+	// Resolvers are the DNS resolvers queried by active sources, if not set default public ones are used.
+	Resolvers []string
+	// Retries is the number of retries of failed DNS queries, on the next resolver.
+	Retries int
+	// Bruteforce holds the settings of the `bruteforce` source.
+	Bruteforce sources.BruteforceConfiguration
+	// Permutation holds the settings of the `permutation` stage.
+	Permutation sources.PermutationConfiguration
+	// ZoneWalk holds the settings of the `zonewalk` source.
+	ZoneWalk sources.ZoneWalkConfiguration
+	// DNSRecords holds the settings of the `dnsrecords` stage.
+	DNSRecords sources.DNSRecordsConfiguration
+	// TLSGrab holds the settings of the `tlsgrab` stage.
+	TLSGrab sources.TLSGrabConfiguration
+	// Crawl holds the settings of the `crawl` stage.
+	Crawl sources.CrawlConfiguration
+	// Takeover holds the settings of the `takeover` stage.
+	Takeover sources.TakeoverConfiguration
+	// Checkpoint, if set, records progress so an interrupted run can be resumed.
+	Checkpoint *checkpoint.Checkpoint
+	// Filter, if set, keeps only subdomains matching its include and exclude patterns.
+	Filter *filter.Filter
+	// Normalizer canonicalizes and validates subdomains, if not set a default one is used.
+	Normalizer *normalizer.Normalizer
+	// Enrichment holds the settings of the enrichment of subdomains with their
+	// addresses, done if turned on.
+	Enrichment enrichment.Configuration
+	// Probe holds the settings of the probing of the web servers of the
+	// subdomains kept, done if enabled.
+	Probe sources.ProbeConfiguration
+	// Attributions, if true, reports subdomains found again by other sources
+	// than the one that found them first, as attribution results.
+	Attributions bool
+	// Graph, if set, aggregates the results found into a graph of the
+	// namespace of the domains searched, attributions included.
+	Graph *graph.Graph
 }
 
 // dp is a domain parser used to normalize domains into their root and top-level domain (TLD) components.
@@ -131,9 +633,64 @@ func New(cfg *Configuration) (finder *Finder, err error) {
 	// Initialize a Finder instance with an empty map of sources and the provided configuration.
 	finder = &Finder{
 		sources: map[string]sources.Source{},
+		stages:  map[string]sources.Stage{},
 		configuration: &sources.Configuration{
-			Keys: cfg.Keys,
+			Keys:       cfg.Keys,
+			CTLog:      cfg.CTLog,
+			GitLab:     cfg.GitLab,
+			LocalFS:    cfg.LocalFS,
+			Import:     cfg.Import,
+			PassiveDNS: cfg.PassiveDNS,
+			HostSearch: cfg.HostSearch,
+			Whois:      cfg.Whois,
+			Resolver: resolver.New(&resolver.Configuration{
+				Servers: cfg.Resolvers,
+				Retries: cfg.Retries,
+			}),
+			Bruteforce:  cfg.Bruteforce,
+			Permutation: cfg.Permutation,
+			ZoneWalk:    cfg.ZoneWalk,
+			DNSRecords:  cfg.DNSRecords,
+			TLSGrab:     cfg.TLSGrab,
+			Crawl:       cfg.Crawl,
+			Takeover:    cfg.Takeover,
 		},
+		checkpoint:   cfg.Checkpoint,
+		filter:       cfg.Filter,
+		normalizer:   cfg.Normalizer,
+		attributions: cfg.Attributions || cfg.Graph != nil,
+		graph:        cfg.Graph,
+	}
+
+	if finder.normalizer == nil {
+		finder.normalizer = normalizer.New(&normalizer.Configuration{})
+	}
+
+	if cfg.Enrichment.Enabled() {
+		finder.enricher, err = enrichment.New(&cfg.Enrichment, finder.configuration.Resolver)
+		if err != nil {
+			return
+		}
+	}
+
+	if cfg.Probe.Enabled {
+		finder.prober, err = probe.New(&cfg.Probe)
+		if err != nil {
+			return
+		}
+
+		finder.cluster = cfg.Probe.Cluster
+		finder.maxDistance = -1
+
+		if cfg.Probe.MaxDistance != nil {
+			finder.maxDistance = *cfg.Probe.MaxDistance
+		}
+	}
+
+	// Only set Cursors when a checkpoint is given, a nil *checkpoint.Checkpoint
+	// stored in the interface would not compare equal to nil.
+	if cfg.Checkpoint != nil {
+		finder.configuration.Cursors = cfg.Checkpoint
 	}
 
 	// If no specific sources are provided, use the default list of all sources.
@@ -141,14 +698,19 @@ func New(cfg *Configuration) (finder *Finder, err error) {
 		cfg.SourcesToUSe = sources.List
 	}
 
+	// Opt-in sources, never part of the default list, are added on request.
+	sourcesToUse := append(append([]string{}, cfg.SourcesToUSe...), cfg.SourcesToInclude...)
+
 	// Loop through the selected sources and initialize each one.
-	for _, source := range cfg.SourcesToUSe {
+	for _, source := range sourcesToUse {
 		// Depending on the source name, initialize the appropriate source and add it to the map.
 		switch source {
 		case sources.ANUBIS:
 			finder.sources[source] = &anubis.Source{}
 		case sources.BEVIGIL:
 			finder.sources[source] = &bevigil.Source{}
+		case sources.BRUTEFORCE:
+			finder.sources[source] = &bruteforce.Source{}
 		case sources.BUILTWITH:
 			finder.sources[source] = &builtwith.Source{}
 		case sources.CENSYS:
@@ -159,34 +721,70 @@ func New(cfg *Configuration) (finder *Finder, err error) {
 			finder.sources[source] = &certspotter.Source{}
 		case sources.CHAOS:
 			finder.sources[source] = &chaos.Source{}
+		case sources.CIRCL:
+			finder.sources[source] = &circl.Source{}
 		case sources.COMMONCRAWL:
 			finder.sources[source] = &commoncrawl.Source{}
+		case sources.CRAWL:
+			finder.stages[source] = &crawl.Stage{}
 		case sources.CRTSH:
 			finder.sources[source] = &crtsh.Source{}
+		case sources.CTLOG:
+			finder.sources[source] = &ctlog.Source{}
+		case sources.DNSDB:
+			finder.sources[source] = &dnsdb.Source{}
+		case sources.DNSRECORDS:
+			finder.stages[source] = &dnsrecords.Stage{}
+		case sources.FOFA:
+			finder.sources[source] = &fofa.Source{}
 		case sources.FULLHUNT:
 			finder.sources[source] = &fullhunt.Source{}
 		case sources.GITHUB:
 			finder.sources[source] = &github.Source{}
+		case sources.GITLAB:
+			finder.sources[source] = &gitlab.Source{}
 		case sources.HACKERTARGET:
 			finder.sources[source] = &hackertarget.Source{}
+		case sources.HUNTER:
+			finder.sources[source] = &hunter.Source{}
+		case sources.IMPORT:
+			finder.sources[source] = &importer.Source{}
 		case sources.INTELLIGENCEX:
 			finder.sources[source] = &intelx.Source{}
 		case sources.LEAKIX:
 			finder.sources[source] = &leakix.Source{}
+		case sources.LOCALFS:
+			finder.sources[source] = &localfs.Source{}
+		case sources.MNEMONIC:
+			finder.sources[source] = &mnemonic.Source{}
+		case sources.NETLAS:
+			finder.sources[source] = &netlas.Source{}
 		case sources.OPENTHREATEXCHANGE:
 			finder.sources[source] = &otx.Source{}
+		case sources.PERMUTATION:
+			finder.stages[source] = &permutation.Stage{}
 		case sources.SECURITYTRAILS:
 			finder.sources[source] = &securitytrails.Source{}
 		case sources.SHODAN:
 			finder.sources[source] = &shodan.Source{}
 		case sources.SUBDOMAINCENTER:
 			finder.sources[source] = &subdomaincenter.Source{}
+		case sources.TAKEOVER:
+			finder.takeover = &takeover.Stage{}
+		case sources.TLSGRAB:
+			finder.stages[source] = &tlsgrab.Stage{}
 		case sources.URLSCAN:
 			finder.sources[source] = &urlscan.Source{}
 		case sources.WAYBACK:
 			finder.sources[source] = &wayback.Source{}
+		case sources.WHOIS:
+			finder.stages[source] = &whois.Stage{}
 		case sources.VIRUSTOTAL:
 			finder.sources[source] = &virustotal.Source{}
+		case sources.ZONEWALK:
+			finder.sources[source] = &zonewalk.Source{}
+		case sources.ZOOMEYE:
+			finder.sources[source] = &zoomeye.Source{}
 		}
 	}
 
@@ -195,6 +793,11 @@ func New(cfg *Configuration) (finder *Finder, err error) {
 		source := cfg.SourcesToExclude[index]
 
 		delete(finder.sources, source)
+		delete(finder.stages, source)
+
+		if source == sources.TAKEOVER {
+			finder.takeover = nil
+		}
 	}
 
 	// Return the Finder instance with all the selected sources.
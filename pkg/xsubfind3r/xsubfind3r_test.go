@@ -0,0 +1,199 @@
+package xsubfind3r
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/checkpoint"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/enrichment"
+	"github.com/hueristiq/xsubfind3r/pkg/xsubfind3r/sources"
+)
+
+// fakeSource reports the subdomains it is given, each with an address.
+type fakeSource struct {
+	name       string
+	subdomains map[string]string
+}
+
+func (source *fakeSource) Run(_ context.Context, _ *sources.Configuration, _ string) <-chan sources.Result {
+	results := make(chan sources.Result, 2*len(source.subdomains))
+
+	for subdomain, ip := range source.subdomains {
+		results <- sources.Result{Type: sources.ResultSubdomain, Source: source.name, Value: subdomain}
+		results <- sources.Result{Type: sources.ResultIP, Source: source.name, Subdomain: subdomain, Value: ip}
+	}
+
+	close(results)
+
+	return results
+}
+
+func (source *fakeSource) Name() string {
+	return source.name
+}
+
+func TestFindDropsAttributionsOfExcludedSubdomains(t *testing.T) {
+	database := filepath.Join(t.TempDir(), "ip2asn.tsv")
+
+	ranges := "192.0.2.0\t192.0.2.255\t64500\tUS\tEXCLUDED\n198.51.100.0\t198.51.100.255\t64501\tUS\tKEPT\n"
+
+	if err := os.WriteFile(database, []byte(ranges), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	finder, err := New(&Configuration{
+		SourcesToUSe: []string{sources.IMPORT},
+		Enrichment: enrichment.Configuration{
+			Databases:  []string{database},
+			ExcludeASN: []string{"AS64500"},
+		},
+		Attributions: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subdomains := map[string]string{
+		"excluded.example.com": "192.0.2.1",
+		"kept.example.com":     "198.51.100.1",
+	}
+
+	finder.sources = map[string]sources.Source{
+		"one": &fakeSource{name: "one", subdomains: subdomains},
+		"two": &fakeSource{name: "two", subdomains: subdomains},
+	}
+
+	var found, attributed []string
+
+	for result := range finder.Find(context.Background(), "example.com") {
+		switch result.Type {
+		case sources.ResultSubdomain:
+			found = append(found, result.Value)
+		case sources.ResultAttribution:
+			attributed = append(attributed, result.Value)
+		}
+	}
+
+	if want := []string{"kept.example.com"}; !slices.Equal(found, want) {
+		t.Errorf("subdomains = %v, want %v", found, want)
+	}
+
+	if want := []string{"kept.example.com"}; !slices.Equal(attributed, want) {
+		t.Errorf("attributions = %v, want %v", attributed, want)
+	}
+}
+
+// fakeStage records the subdomains it runs on and reports those it is given.
+type fakeStage struct {
+	name       string
+	subdomains []string
+
+	input []string
+}
+
+func (stage *fakeStage) Run(_ context.Context, _ *sources.Configuration, _ string, subdomains []string) <-chan sources.Result {
+	stage.input = subdomains
+
+	results := make(chan sources.Result, len(stage.subdomains))
+
+	for _, subdomain := range stage.subdomains {
+		results <- sources.Result{Type: sources.ResultSubdomain, Source: stage.name, Value: subdomain}
+	}
+
+	close(results)
+
+	return results
+}
+
+func (stage *fakeStage) Name() string {
+	return stage.name
+}
+
+func TestFindRunsStagesOnSubdomainsWrittenBeforeResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+
+	progress, err := checkpoint.Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progress.MarkWritten("example.com", "old.example.com")
+	progress.MarkSourceDone("example.com", "one")
+
+	if err = progress.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err = checkpoint.Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		progress.Close()
+	})
+
+	finder, err := New(&Configuration{
+		SourcesToUSe: []string{sources.IMPORT},
+		Checkpoint:   progress,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stage := &fakeStage{name: "stage"}
+
+	finder.sources = map[string]sources.Source{
+		"one": &fakeSource{name: "one", subdomains: map[string]string{"old.example.com": "192.0.2.1"}},
+		"two": &fakeSource{name: "two", subdomains: map[string]string{"new.example.com": "192.0.2.2", "old.example.com": "192.0.2.1"}},
+	}
+	finder.stages = map[string]sources.Stage{"stage": stage}
+
+	for range finder.Find(context.Background(), "example.com") {
+	}
+
+	slices.Sort(stage.input)
+
+	if want := []string{"new.example.com", "old.example.com"}; !slices.Equal(stage.input, want) {
+		t.Errorf("stage input = %v, want %v", stage.input, want)
+	}
+}
+
+func TestFindRunsTakeoverOnSubdomainsKept(t *testing.T) {
+	database := filepath.Join(t.TempDir(), "ip2asn.tsv")
+
+	if err := os.WriteFile(database, []byte("192.0.2.0\t192.0.2.255\t64500\tUS\tEXCLUDED\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	finder, err := New(&Configuration{
+		SourcesToUSe: []string{sources.IMPORT},
+		Enrichment: enrichment.Configuration{
+			Databases:  []string{database},
+			ExcludeASN: []string{"AS64500"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	takeover := &fakeStage{name: sources.TAKEOVER}
+
+	finder.sources = map[string]sources.Source{
+		"one": &fakeSource{name: "one", subdomains: map[string]string{"excluded.example.com": "192.0.2.1", "www.example.com": "198.51.100.1"}},
+	}
+	finder.stages = map[string]sources.Stage{"stage": &fakeStage{name: "stage", subdomains: []string{"api.example.com"}}}
+	finder.takeover = takeover
+
+	for range finder.Find(context.Background(), "example.com") {
+	}
+
+	slices.Sort(takeover.input)
+
+	if want := []string{"api.example.com", "www.example.com"}; !slices.Equal(takeover.input, want) {
+		t.Errorf("takeover input = %v, want %v", takeover.input, want)
+	}
+}